@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/govau/notify-client-go/internal/ratelimit"
+)
+
+const defaultBulkConcurrency = 10
+
+// BulkRecipient is a recipient of a message sent by a BulkSender.
+type BulkRecipient struct {
+	// To is the phone number or email address to send the message to.
+	To              string
+	Personalisation Personalisation
+	Reference       string
+}
+
+// BulkResult is the result of sending a message to one recipient.
+type BulkResult struct {
+	// Index is the position of the recipient in the stream, starting at 0.
+	Index     int
+	Recipient BulkRecipient
+	// ID is the ID of the notification if it was sent.
+	ID  string
+	Err error
+}
+
+// BulkStats summarises the messages sent by a BulkSender.
+type BulkStats struct {
+	Sent    int
+	Failed  int
+	Elapsed time.Duration
+}
+
+type bulkConfig struct {
+	concurrency int
+	limiter     *ratelimit.Limiter
+}
+
+type BulkOption func(*bulkConfig) error
+
+// BulkConcurrency sets how many messages a BulkSender sends at once. The
+// default is 10.
+func BulkConcurrency(n int) BulkOption {
+	return func(c *bulkConfig) error {
+		if n < 1 {
+			return errors.New("notify: bulk concurrency must be at least 1")
+		}
+		c.concurrency = n
+		return nil
+	}
+}
+
+// BulkRateLimit limits a BulkSender to sending limit messages every per, such
+// as 3000 every minute.
+func BulkRateLimit(limit int, per time.Duration) BulkOption {
+	return func(c *bulkConfig) error {
+		if limit < 1 || per <= 0 {
+			return errors.New("notify: bulk rate limit must allow at least one message in a positive period")
+		}
+		c.limiter = ratelimit.New(limit, per)
+		return nil
+	}
+}
+
+// BulkSender sends a template to a stream of recipients using a fixed number
+// of workers.
+type BulkSender struct {
+	client Notifier
+	config bulkConfig
+}
+
+// NewBulkSender returns a BulkSender which sends messages with client.
+func NewBulkSender(client Notifier, options ...BulkOption) (*BulkSender, error) {
+	config := bulkConfig{concurrency: defaultBulkConcurrency}
+	for _, option := range options {
+		if err := option(&config); err != nil {
+			return nil, err
+		}
+	}
+	return &BulkSender{client: client, config: config}, nil
+}
+
+// SendSMS sends the template to every recipient received from recipients,
+// until the channel is closed or ctx is done. The options are used for every
+// message.
+//
+// onResult, if not nil, is called with the result for each recipient. Calls to
+// onResult are not concurrent, but are in the order messages finish sending.
+// The returned error is ctx.Err() if ctx was done before recipients was
+// closed, in which case the remaining recipients are not read.
+func (b *BulkSender) SendSMS(
+	ctx context.Context,
+	id string,
+	recipients <-chan BulkRecipient,
+	onResult func(BulkResult),
+	options ...SendSMSOption,
+) (BulkStats, error) {
+	return b.run(ctx, recipients, onResult, func(ctx context.Context, r BulkRecipient) (string, error) {
+		var smsOptions []SendSMSOption
+		for _, option := range r.options() {
+			smsOptions = append(smsOptions, option)
+		}
+		sent, err := b.client.SendSMSContext(ctx, id, r.To, append(smsOptions, options...)...)
+		return sent.ID, err
+	})
+}
+
+// SendEmail sends the template to every recipient received from recipients,
+// in the same way as SendSMS.
+func (b *BulkSender) SendEmail(
+	ctx context.Context,
+	id string,
+	recipients <-chan BulkRecipient,
+	onResult func(BulkResult),
+	options ...SendEmailOption,
+) (BulkStats, error) {
+	return b.run(ctx, recipients, onResult, func(ctx context.Context, r BulkRecipient) (string, error) {
+		var emailOptions []SendEmailOption
+		for _, option := range r.options() {
+			emailOptions = append(emailOptions, option)
+		}
+		sent, err := b.client.SendEmailContext(ctx, id, r.To, append(emailOptions, options...)...)
+		return sent.ID, err
+	})
+}
+
+// options returns the personalisation and reference of the recipient as send
+// options.
+func (r BulkRecipient) options() []CommonOption {
+	var options []CommonOption
+	if r.Personalisation != nil {
+		options = append(options, r.Personalisation)
+	}
+	if r.Reference != "" {
+		options = append(options, Reference(r.Reference))
+	}
+	return options
+}
+
+type bulkJob struct {
+	index     int
+	recipient BulkRecipient
+}
+
+func (b *BulkSender) run(
+	ctx context.Context,
+	recipients <-chan BulkRecipient,
+	onResult func(BulkResult),
+	send func(context.Context, BulkRecipient) (string, error),
+) (BulkStats, error) {
+	start := time.Now()
+
+	var (
+		mu    sync.Mutex
+		stats BulkStats
+		wg    sync.WaitGroup
+	)
+	jobs := make(chan bulkJob)
+
+	report := func(result BulkResult) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if result.Err == nil {
+			stats.Sent++
+		} else {
+			stats.Failed++
+		}
+		if onResult != nil {
+			onResult(result)
+		}
+	}
+
+	for i := 0; i < b.config.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result := BulkResult{Index: job.index, Recipient: job.recipient}
+				if b.config.limiter != nil {
+					result.Err = b.config.limiter.Wait(ctx)
+				}
+				if result.Err == nil {
+					result.ID, result.Err = send(ctx, job.recipient)
+				}
+
+				report(result)
+			}
+		}()
+	}
+
+	var err error
+	index := 0
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		case recipient, ok := <-recipients:
+			if !ok {
+				break loop
+			}
+			select {
+			case jobs <- bulkJob{index, recipient}:
+				index++
+			case <-ctx.Done():
+				err = ctx.Err()
+				report(BulkResult{Index: index, Recipient: recipient, Err: err})
+				break loop
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	stats.Elapsed = time.Since(start)
+	return stats, err
+}
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WithTemplateCache caches the templates returned by GetTemplateByID and
+// GetAllTemplates for ttl. A given version of a template never changes, so
+// templates returned by GetTemplateByIDAndVersion are cached until they are
+// invalidated. ttl must be positive.
+func WithTemplateCache(ttl time.Duration) ClientOption {
+	return func(c Client) (Client, error) {
+		if ttl <= 0 {
+			return c, errors.New("notify: template cache ttl must be positive")
+		}
+		c.templates = newTemplateCache(ttl)
+		return c, nil
+	}
+}
+
+// InvalidateTemplate removes the template with the given ID from the client's
+// caches, so that the next lookup fetches it from Notify.
+func (c Client) InvalidateTemplate(id string) {
+	if c.templates != nil {
+		c.templates.invalidate(id)
+	}
+	if c.validator != nil {
+		c.validator.templates.invalidate(id)
+	}
+}
+
+// InvalidateTemplates removes every template from the client's caches.
+func (c Client) InvalidateTemplates() {
+	if c.templates != nil {
+		c.templates.invalidateAll()
+	}
+	if c.validator != nil {
+		c.validator.templates.invalidateAll()
+	}
+}
+
+type templateVersion struct {
+	id      string
+	version int
+}
+
+type cachedTemplate struct {
+	template Template
+	expires  time.Time
+}
+
+type cachedTemplates struct {
+	templates Templates
+	expires   time.Time
+}
+
+// templateCache is a cache of templates which is safe for concurrent use.
+type templateCache struct {
+	// ttl is how long the latest templates and lists of templates are cached
+	// for.
+	ttl time.Duration
+
+	mu       sync.RWMutex
+	latest   map[string]cachedTemplate
+	versions map[templateVersion]Template
+	lists    map[TemplateType]cachedTemplates
+}
+
+func newTemplateCache(ttl time.Duration) *templateCache {
+	return &templateCache{
+		ttl:      ttl,
+		latest:   map[string]cachedTemplate{},
+		versions: map[templateVersion]Template{},
+		lists:    map[TemplateType]cachedTemplates{},
+	}
+}
+
+func (tc *templateCache) expires() time.Time {
+	return time.Now().Add(tc.ttl)
+}
+
+func fresh(expires time.Time) bool {
+	return time.Now().Before(expires)
+}
+
+func (tc *templateCache) getLatest(id string) (Template, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	cached, ok := tc.latest[id]
+	if !ok || !fresh(cached.expires) {
+		return Template{}, false
+	}
+	return cached.template, true
+}
+
+func (tc *templateCache) putLatest(t Template) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.latest[t.ID] = cachedTemplate{t, tc.expires()}
+	tc.versions[templateVersion{t.ID, t.Version}] = t
+}
+
+func (tc *templateCache) getVersion(id string, version int) (Template, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	t, ok := tc.versions[templateVersion{id, version}]
+	return t, ok
+}
+
+func (tc *templateCache) putVersion(t Template) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.versions[templateVersion{t.ID, t.Version}] = t
+}
+
+func (tc *templateCache) getList(typ TemplateType) (Templates, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	cached, ok := tc.lists[typ]
+	if !ok || !fresh(cached.expires) {
+		return nil, false
+	}
+	return append(Templates(nil), cached.templates...), true
+}
+
+func (tc *templateCache) putList(typ TemplateType, templates Templates) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.lists[typ] = cachedTemplates{append(Templates(nil), templates...), tc.expires()}
+}
+
+func (tc *templateCache) invalidate(id string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	delete(tc.latest, id)
+	for key := range tc.versions {
+		if key.id == id {
+			delete(tc.versions, key)
+		}
+	}
+	tc.lists = map[TemplateType]cachedTemplates{}
+}
+
+func (tc *templateCache) invalidateAll() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.latest = map[string]cachedTemplate{}
+	tc.versions = map[templateVersion]Template{}
+	tc.lists = map[TemplateType]cachedTemplates{}
+}
+
+// getTemplate returns the latest version of a template from tc, fetching it
+// with c if it is not cached.
+func (tc *templateCache) getTemplate(ctx context.Context, c Client, id string) (Template, error) {
+	if t, ok := tc.getLatest(id); ok {
+		return t, nil
+	}
+
+	t, err := c.GetTemplateByIDContext(ctx, id)
+	if err != nil {
+		return t, err
+	}
+
+	tc.putLatest(t)
+	return t, nil
+}
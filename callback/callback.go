@@ -0,0 +1,92 @@
+// Package callback provides HTTP handlers for receiving callbacks from Notify.
+//
+// Notify sends callbacks as a JSON POST request, authenticated with the bearer
+// token you configured for the callback. If the handler responds with an error
+// status, Notify will retry the callback later.
+package callback
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	notify "github.com/govau/notify-client-go"
+)
+
+// DeliveryReceipt is the delivery status of a notification, sent to the status
+// callback URL for your service or for the notification.
+type DeliveryReceipt struct {
+	ID               string                    `json:"id"`
+	Reference        *string                   `json:"reference"`
+	To               string                    `json:"to"`
+	Status           notify.NotificationStatus `json:"status"`
+	CreatedAt        time.Time                 `json:"created_at"`
+	CompletedAt      *time.Time                `json:"completed_at"`
+	SentAt           *time.Time                `json:"sent_at"`
+	NotificationType notify.TemplateType       `json:"notification_type"`
+	TemplateID       string                    `json:"template_id"`
+	TemplateVersion  int                       `json:"template_version"`
+}
+
+// DeliveryStatusFunc handles a delivery receipt. Returning an error causes
+// Notify to retry the callback.
+type DeliveryStatusFunc func(ctx context.Context, receipt DeliveryReceipt) error
+
+// DeliveryStatusHandler returns a handler for delivery status callbacks which
+// are authenticated with bearerToken.
+func DeliveryStatusHandler(bearerToken string, fn DeliveryStatusFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var receipt DeliveryReceipt
+		if !decode(w, r, bearerToken, &receipt) {
+			return
+		}
+
+		if err := fn(r.Context(), receipt); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// decode authenticates the request and decodes its JSON body into v. If this
+// fails, an error response is written and decode returns false.
+func decode(w http.ResponseWriter, r *http.Request, bearerToken string, v interface{}) bool {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return false
+	}
+
+	if !authorized(r, bearerToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid callback body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func authorized(r *http.Request, bearerToken string) bool {
+	if bearerToken == "" {
+		return false
+	}
+
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+
+	token := strings.TrimPrefix(header, prefix)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(bearerToken)) == 1
+}
@@ -0,0 +1,241 @@
+package callback_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/callback"
+)
+
+const deliveryReceipt = `{
+	"id": "740e5834-3a29-46b4-9a6f-16142fde533a",
+	"reference": "12345678",
+	"to": "0400000000",
+	"status": "delivered",
+	"created_at": "2019-05-01T01:02:03.000000Z",
+	"completed_at": "2019-05-01T01:02:05.000000Z",
+	"sent_at": "2019-05-01T01:02:04.000000Z",
+	"notification_type": "sms",
+	"template_id": "f33517ff-2a88-4f6e-b855-c550268ce08a",
+	"template_version": 1
+}`
+
+func TestDeliveryStatusHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		auth       string
+		body       string
+		fnErr      error
+		wantStatus int
+		wantCalled bool
+	}{
+		{
+			name:       "delivered",
+			method:     "POST",
+			auth:       "Bearer 1234567890",
+			body:       deliveryReceipt,
+			wantStatus: http.StatusNoContent,
+			wantCalled: true,
+		},
+		{
+			name:       "wrong token",
+			method:     "POST",
+			auth:       "Bearer 0987654321",
+			body:       deliveryReceipt,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing token",
+			method:     "POST",
+			body:       deliveryReceipt,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong method",
+			method:     "GET",
+			auth:       "Bearer 1234567890",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "invalid body",
+			method:     "POST",
+			auth:       "Bearer 1234567890",
+			body:       "<html>",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "handler failed",
+			method:     "POST",
+			auth:       "Bearer 1234567890",
+			body:       deliveryReceipt,
+			fnErr:      errors.New("database unavailable"),
+			wantStatus: http.StatusInternalServerError,
+			wantCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			var got callback.DeliveryReceipt
+
+			handler := callback.DeliveryStatusHandler("1234567890", func(ctx context.Context, receipt callback.DeliveryReceipt) error {
+				called = true
+				got = receipt
+				return tt.fnErr
+			})
+
+			req := httptest.NewRequest(tt.method, "/callback", strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if called != tt.wantCalled {
+				t.Fatalf("got called %v, want %v", called, tt.wantCalled)
+			}
+			if !called {
+				return
+			}
+			if got.Status != notify.StatusDelivered {
+				t.Errorf("got status %v, want delivered", got.Status)
+			}
+			if want := time.Date(2019, 5, 1, 1, 2, 3, 0, time.UTC); !got.CreatedAt.Equal(want) {
+				t.Errorf("got created at %v, want %v", got.CreatedAt, want)
+			}
+			if got.Reference == nil || *got.Reference != "12345678" {
+				t.Errorf("got reference %v, want 12345678", got.Reference)
+			}
+			if got.TemplateVersion != 1 {
+				t.Errorf("got template version %d, want 1", got.TemplateVersion)
+			}
+		})
+	}
+}
+
+const receivedText = `{
+	"id": "b51f638b-4295-46c0-a06e-cd41eee7a3b1",
+	"source_number": "61400000000",
+	"destination_number": "61411111111",
+	"message": "Yes please",
+	"date_received": "2019-05-01T01:02:03.000000Z"
+}`
+
+func TestReceivedTextHandler(t *testing.T) {
+	var texts []notify.ReceivedText
+	fail := true
+
+	deduper, err := callback.NewMemoryDeduper(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := callback.ReceivedTextHandler(
+		"1234567890",
+		func(ctx context.Context, text notify.ReceivedText) error {
+			texts = append(texts, text)
+			if fail {
+				fail = false
+				return errors.New("database unavailable")
+			}
+			return nil
+		},
+		callback.WithDeduper(deduper),
+	)
+
+	send := func() int {
+		req := httptest.NewRequest("POST", "/inbound", strings.NewReader(receivedText))
+		req.Header.Set("Authorization", "Bearer 1234567890")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for i, want := range []int{
+		http.StatusInternalServerError,
+		http.StatusNoContent,
+		http.StatusNoContent,
+	} {
+		if got := send(); got != want {
+			t.Errorf("delivery %d: got status %d, want %d", i+1, got, want)
+		}
+	}
+
+	if len(texts) != 2 {
+		t.Fatalf("got %d calls, want 2 (a failed call and its retry)", len(texts))
+	}
+
+	want := notify.ReceivedText{
+		ID:           "b51f638b-4295-46c0-a06e-cd41eee7a3b1",
+		UserNumber:   "61400000000",
+		NotifyNumber: "61411111111",
+		Content:      "Yes please",
+		CreatedAt:    time.Date(2019, 5, 1, 1, 2, 3, 0, time.UTC),
+	}
+	if texts[1] != want {
+		t.Errorf("got %+v, want %+v", texts[1], want)
+	}
+}
+
+// releaseFailingDeduper claims every ID and fails to release them.
+type releaseFailingDeduper struct {
+	releaseErr error
+}
+
+func (d *releaseFailingDeduper) Claim(ctx context.Context, id string) (bool, error) {
+	return true, nil
+}
+
+func (d *releaseFailingDeduper) Release(ctx context.Context, id string) error {
+	d.releaseErr = ctx.Err()
+	return errors.New("store unavailable")
+}
+
+func TestReceivedTextHandlerRelease(t *testing.T) {
+	deduper := &releaseFailingDeduper{}
+	var logs bytes.Buffer
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler := callback.ReceivedTextHandler(
+		"1234567890",
+		func(ctx context.Context, text notify.ReceivedText) error {
+			cancel()
+			return ctx.Err()
+		},
+		callback.WithDeduper(deduper),
+		callback.WithErrorLog(log.New(&logs, "", 0)),
+	)
+
+	req := httptest.NewRequest("POST", "/inbound", strings.NewReader(receivedText)).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer 1234567890")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if deduper.releaseErr != nil {
+		t.Errorf("released with a done context: %v", deduper.releaseErr)
+	}
+	if !strings.Contains(logs.String(), "store unavailable") {
+		t.Errorf("release error was not logged: %q", logs.String())
+	}
+
+	if _, err := callback.NewMemoryDeduper(0); err == nil {
+		t.Error("expected an error for a zero ttl")
+	}
+}
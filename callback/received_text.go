@@ -0,0 +1,187 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	notify "github.com/govau/notify-client-go"
+)
+
+// receivedTextCallback is the body of a received text message callback.
+type receivedTextCallback struct {
+	ID                string    `json:"id"`
+	SourceNumber      string    `json:"source_number"`
+	DestinationNumber string    `json:"destination_number"`
+	Message           string    `json:"message"`
+	DateReceived      time.Time `json:"date_received"`
+}
+
+func (cb receivedTextCallback) receivedText() notify.ReceivedText {
+	return notify.ReceivedText{
+		ID:           cb.ID,
+		UserNumber:   cb.SourceNumber,
+		NotifyNumber: cb.DestinationNumber,
+		Content:      cb.Message,
+		CreatedAt:    cb.DateReceived,
+	}
+}
+
+// ReceivedTextFunc handles a text message sent to your service's inbound
+// number. Returning an error causes Notify to retry the callback.
+type ReceivedTextFunc func(ctx context.Context, text notify.ReceivedText) error
+
+// Deduper records which callbacks have been processed, so that a callback
+// Notify delivers more than once is only handled once.
+type Deduper interface {
+	// Claim marks id as being processed, returning false if it has already
+	// been claimed.
+	Claim(ctx context.Context, id string) (bool, error)
+	// Release removes the claim on id after processing it failed, so that a
+	// retried callback is processed.
+	Release(ctx context.Context, id string) error
+}
+
+// releaseTimeout is how long the handler waits for a Deduper to release a
+// claim.
+const releaseTimeout = 10 * time.Second
+
+type handlerConfig struct {
+	deduper  Deduper
+	errorLog *log.Logger
+}
+
+func (c handlerConfig) logf(format string, args ...interface{}) {
+	if c.errorLog != nil {
+		c.errorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+type HandlerOption func(*handlerConfig)
+
+// WithDeduper skips callbacks which have already been claimed by d.
+func WithDeduper(d Deduper) HandlerOption {
+	return func(c *handlerConfig) {
+		c.deduper = d
+	}
+}
+
+// WithErrorLog logs errors which cannot be reported to Notify, such as a
+// failure to release a claim, to l. By default they are logged with the log
+// package's standard logger.
+func WithErrorLog(l *log.Logger) HandlerOption {
+	return func(c *handlerConfig) {
+		c.errorLog = l
+	}
+}
+
+// detachedContext carries the values of a context without its cancellation,
+// so that cleanup can finish after a request is cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// ReceivedTextHandler returns a handler for received text message callbacks
+// which are authenticated with bearerToken.
+func ReceivedTextHandler(bearerToken string, fn ReceivedTextFunc, options ...HandlerOption) http.Handler {
+	var config handlerConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var cb receivedTextCallback
+		if !decode(w, r, bearerToken, &cb) {
+			return
+		}
+
+		ctx := r.Context()
+
+		if config.deduper != nil {
+			claimed, err := config.deduper.Claim(ctx, cb.ID)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if !claimed {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+
+		if err := fn(ctx, cb.receivedText()); err != nil {
+			if config.deduper != nil {
+				releaseCtx, cancel := context.WithTimeout(detachedContext{ctx}, releaseTimeout)
+				if err := config.deduper.Release(releaseCtx, cb.ID); err != nil {
+					config.logf("callback: releasing received text %s: %v", cb.ID, err)
+				}
+				cancel()
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// MemoryDeduper is a Deduper which remembers claimed IDs in memory. It is only
+// suitable when callbacks are handled by a single process.
+type MemoryDeduper struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	claimed   map[string]time.Time
+	lastPrune time.Time
+}
+
+// NewMemoryDeduper returns a MemoryDeduper which forgets IDs after ttl, which
+// must be positive.
+func NewMemoryDeduper(ttl time.Duration) (*MemoryDeduper, error) {
+	if ttl <= 0 {
+		return nil, errors.New("callback: deduper ttl must be positive")
+	}
+	return &MemoryDeduper{
+		ttl:     ttl,
+		claimed: map[string]time.Time{},
+	}, nil
+}
+
+func (d *MemoryDeduper) Claim(ctx context.Context, id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(d.lastPrune) > d.ttl {
+		for claimedID, at := range d.claimed {
+			if now.Sub(at) > d.ttl {
+				delete(d.claimed, claimedID)
+			}
+		}
+		d.lastPrune = now
+	}
+
+	if at, ok := d.claimed[id]; ok && now.Sub(at) <= d.ttl {
+		return false, nil
+	}
+
+	d.claimed[id] = now
+	return true, nil
+}
+
+func (d *MemoryDeduper) Release(ctx context.Context, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.claimed, id)
+	return nil
+}
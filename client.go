@@ -2,29 +2,160 @@ package notify
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/govau/notify-client-go/internal/base"
+	"github.com/govau/notify-client-go/internal/ratelimit"
 )
 
 type Client struct {
-	c base.Client
+	c         base.Client
+	validator *validator
+	templates *templateCache
 }
 
-type ClientOption func(base.Client) (base.Client, error)
+type ClientOption func(Client) (Client, error)
 
 func WithBaseURL(target string) ClientOption {
-	return func(c base.Client) (base.Client, error) {
+	return func(c Client) (Client, error) {
 		baseURL, err := url.Parse(target)
-		c.BaseURL = baseURL
+		c.c.BaseURL = baseURL
 		return c, err
 	}
 }
 
+// WithHTTPClient sends requests with a copy of hc, including its transport,
+// timeout and cookie jar.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c Client) (Client, error) {
+		if hc == nil {
+			return c, errors.New("notify: http client is nil")
+		}
+		c.c.Client = *hc
+		return c, nil
+	}
+}
+
+// WithTimeout limits the time taken by each request, including reading the
+// response body.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c Client) (Client, error) {
+		c.c.Client.Timeout = timeout
+		return c, nil
+	}
+}
+
+// WithTransport sends requests with transport, such as an http.Transport
+// configured with a proxy or client certificates.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c Client) (Client, error) {
+		c.c.Client.Transport = transport
+		return c, nil
+	}
+}
+
+// WithRouteSecret sends secret in the X-Custom-Forwarder header of every
+// request, for gateways in front of Notify which require it.
+func WithRouteSecret(secret string) ClientOption {
+	return func(c Client) (Client, error) {
+		c.c.RouteSecret = secret
+		return c, nil
+	}
+}
+
+// RetryPolicy controls how requests that fail with a transient error, such as
+// a 429 or 503 response, are retried. Requests which send a notification are
+// only retried when Notify reports that it did not process them.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made, including the first.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, doubling for each retry
+	// after that. Defaults to 500ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the longest delay between attempts, unless the server asks
+	// for a longer delay with a Retry-After header. Defaults to 30s.
+	MaxBackoff time.Duration
+}
+
+// WithRetryPolicy enables retrying requests which fail with a transient error.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c Client) (Client, error) {
+		if policy.MaxAttempts < 1 {
+			return c, errors.New("notify: retry policy must allow at least one attempt")
+		}
+		c.c.RetryPolicy = &base.RetryPolicy{
+			MaxAttempts: policy.MaxAttempts,
+			MinBackoff:  policy.MinBackoff,
+			MaxBackoff:  policy.MaxBackoff,
+		}
+		return c, nil
+	}
+}
+
+// WithoutRetry returns a context which disables retries for any call made with
+// it, regardless of the client's retry policy.
+func WithoutRetry(ctx context.Context) context.Context {
+	return base.WithoutRetry(ctx)
+}
+
+// KeyType is the type of an API key, which determines its rate limit.
+type KeyType string
+
+const (
+	LiveKey KeyType = "live"
+	TeamKey KeyType = "team"
+	TestKey KeyType = "test"
+)
+
+// keyTypeRateLimit returns the number of requests per minute Notify allows for
+// an API key of the given type.
+func keyTypeRateLimit(keyType KeyType) (int, bool) {
+	switch keyType {
+	case LiveKey, TeamKey, TestKey:
+		return 3000, true
+	}
+	return 0, false
+}
+
+// WithRateLimit limits the client to limit requests every per, counting
+// retries. Requests over the limit block until they are allowed or their
+// context is done, rather than failing with a 429 response. The limit is
+// shared by every copy of the client and every goroutine using it.
+//
+// Notify's daily message limit is not enforced by the client. Sends over the
+// daily limit still fail with a 429 response, which matches
+// notifyapi.ErrRateLimited.
+func WithRateLimit(limit int, per time.Duration) ClientOption {
+	return func(c Client) (Client, error) {
+		if limit < 1 || per <= 0 {
+			return c, errors.New("notify: rate limit must allow at least one request in a positive period")
+		}
+		c.c.Limiter = ratelimit.New(limit, per)
+		return c, nil
+	}
+}
+
+// WithKeyTypeRateLimit limits the client to Notify's rate limit for the type
+// of its API key. Notify currently allows 3000 requests per minute for every
+// key type; use WithRateLimit for a service with a different limit. See
+// WithRateLimit.
+func WithKeyTypeRateLimit(keyType KeyType) ClientOption {
+	return func(c Client) (Client, error) {
+		limit, ok := keyTypeRateLimit(keyType)
+		if !ok {
+			return c, fmt.Errorf("notify: unknown key type %q", keyType)
+		}
+		return WithRateLimit(limit, time.Minute)(c)
+	}
+}
+
 func validateAPIKey(apiKey string) error {
 	if apiKey == "" {
 		return errors.New("api key is empty")
@@ -49,10 +180,12 @@ func NewClient(apiKey string, options ...ClientOption) (*Client, error) {
 		return apiKey[n+start : n+end]
 	}
 
-	client := base.Client{
-		ServiceID:   slice(-73, -37),
-		APIKey:      slice(-36, 0),
-		RouteSecret: "",
+	client := Client{
+		c: base.Client{
+			ServiceID:   slice(-73, -37),
+			APIKey:      slice(-36, 0),
+			RouteSecret: "",
+		},
 	}
 
 	client, err = WithBaseURL(base.NotifyBaseURL)(client)
@@ -67,42 +200,95 @@ func NewClient(apiKey string, options ...ClientOption) (*Client, error) {
 		}
 	}
 
-	return &Client{client}, nil
+	return &client, nil
 }
 
 func (c Client) GetTemplateByID(id string) (Template, error) {
+	return c.GetTemplateByIDContext(context.Background(), id)
+}
+
+func (c Client) GetTemplateByIDContext(ctx context.Context, id string) (Template, error) {
+	if c.templates != nil {
+		if template, ok := c.templates.getLatest(id); ok {
+			return template, nil
+		}
+	}
+
 	var template Template
-	err := c.c.Get("./v2/template/" + id).JSON(&template).Error
+	err := c.c.GetContext(ctx, "./v2/template/"+id).JSON(&template).Error
+	if err == nil && c.templates != nil {
+		c.templates.putLatest(template)
+	}
 	return template, err
 }
 
 func (c Client) GetTemplateByIDAndVersion(id string, version int) (Template, error) {
+	return c.GetTemplateByIDAndVersionContext(context.Background(), id, version)
+}
+
+func (c Client) GetTemplateByIDAndVersionContext(ctx context.Context, id string, version int) (Template, error) {
+	if c.templates != nil {
+		if template, ok := c.templates.getVersion(id, version); ok {
+			return template, nil
+		}
+	}
+
 	url := "/v2/template/" + id + "/version/" + strconv.Itoa(version)
 	var template Template
-	err := c.c.Get(url).JSON(&template).Error
+	err := c.c.GetContext(ctx, url).JSON(&template).Error
+	if err == nil && c.templates != nil {
+		c.templates.putVersion(template)
+	}
 	return template, err
 }
 
-func (c Client) GetAllTemplates(typ string) (Templates, error) {
+// GetAllTemplates returns the latest version of every template of the given
+// type, or of every type if typ is empty.
+func (c Client) GetAllTemplates(typ TemplateType) (Templates, error) {
+	return c.GetAllTemplatesContext(context.Background(), typ)
+}
+
+func (c Client) GetAllTemplatesContext(ctx context.Context, typ TemplateType) (Templates, error) {
+	if c.templates != nil {
+		if templates, ok := c.templates.getList(typ); ok {
+			return templates, nil
+		}
+	}
+
 	url := "./v2/templates"
 	if typ != "" {
-		url += "?type=" + typ
+		url += "?type=" + string(typ)
 	}
 
 	var templates Templates
-	err := c.c.Get(url).JSON(&templates, "templates").Error
+	err := c.c.GetContext(ctx, url).JSON(&templates, "templates").Error
+	if err == nil && c.templates != nil {
+		c.templates.putList(typ, templates)
+	}
 	return templates, err
 }
 
 func (c Client) GetNotificationById(id string) (Notification, error) {
+	return c.GetNotificationByIdContext(context.Background(), id)
+}
+
+func (c Client) GetNotificationByIdContext(ctx context.Context, id string) (Notification, error) {
 	url := "./v2/notifications/" + id
 
 	var notification Notification
-	err := c.c.Get(url).JSON(&notification).Error
+	err := c.c.GetContext(ctx, url).JSON(&notification).Error
 	return notification, err
 }
 
 func (c Client) GenerateTemplatePreview(id string, personalisation ...PersonalisationOption) (TemplatePreview, error) {
+	return c.GenerateTemplatePreviewContext(context.Background(), id, personalisation...)
+}
+
+func (c Client) GenerateTemplatePreviewContext(
+	ctx context.Context,
+	id string,
+	personalisation ...PersonalisationOption,
+) (TemplatePreview, error) {
 	var response TemplatePreview
 	var buf bytes.Buffer
 	var payload payload
@@ -117,7 +303,7 @@ func (c Client) GenerateTemplatePreview(id string, personalisation ...Personalis
 	}
 
 	url := "/v2/template/" + id + "/preview"
-	err = c.c.Post(url, &buf).JSON(&response).Error
+	err = c.c.PostContext(ctx, url, &buf).JSON(&response).Error
 	return response, err
 }
 
@@ -125,6 +311,15 @@ func (c Client) SendEmail(
 	id string,
 	emailAddress string,
 	options ...SendEmailOption,
+) (SentEmail, error) {
+	return c.SendEmailContext(context.Background(), id, emailAddress, options...)
+}
+
+func (c Client) SendEmailContext(
+	ctx context.Context,
+	id string,
+	emailAddress string,
+	options ...SendEmailOption,
 ) (SentEmail, error) {
 	var response SentEmail
 	var buf bytes.Buffer
@@ -137,12 +332,20 @@ func (c Client) SendEmail(
 		p = option.updateEmailPayload(p)
 	}
 
+	if err := p.validate(); err != nil {
+		return response, err
+	}
+
+	if err := c.validatePersonalisation(ctx, id, p); err != nil {
+		return response, err
+	}
+
 	err := json.NewEncoder(&buf).Encode(p)
 	if err != nil {
 		return response, err
 	}
 
-	err = c.c.Post("./v2/notifications/email", &buf).JSON(&response).Error
+	err = c.c.PostContext(ctx, "./v2/notifications/email", &buf).JSON(&response).Error
 	return response, err
 }
 
@@ -150,6 +353,15 @@ func (c Client) SendSMS(
 	id string,
 	phoneNumber string,
 	options ...SendSMSOption,
+) (SentSMS, error) {
+	return c.SendSMSContext(context.Background(), id, phoneNumber, options...)
+}
+
+func (c Client) SendSMSContext(
+	ctx context.Context,
+	id string,
+	phoneNumber string,
+	options ...SendSMSOption,
 ) (SentSMS, error) {
 	var response SentSMS
 	var buf bytes.Buffer
@@ -162,34 +374,42 @@ func (c Client) SendSMS(
 		p = option.updateSMSPayload(p)
 	}
 
+	if err := p.validate(); err != nil {
+		return response, err
+	}
+
+	if err := c.validatePersonalisation(ctx, id, p); err != nil {
+		return response, err
+	}
+
 	err := json.NewEncoder(&buf).Encode(p)
 	if err != nil {
 		return response, err
 	}
 
-	err = c.c.Post("./v2/notifications/sms", &buf).JSON(&response).Error
+	err = c.c.PostContext(ctx, "./v2/notifications/sms", &buf).JSON(&response).Error
 	return response, err
 }
 
 type Template struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name"`
-	Type      string `json:"type"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
-	CreatedBy string `json:"created_by"`
-	Version   int    `json:"version"`
-	Subject   string `json:"subject"`
-	Body      string `json:"body"`
+	ID        string       `json:"id,omitempty"`
+	Name      string       `json:"name"`
+	Type      TemplateType `json:"type"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt *time.Time   `json:"updated_at"`
+	CreatedBy string       `json:"created_by"`
+	Version   int          `json:"version"`
+	Subject   string       `json:"subject"`
+	Body      string       `json:"body"`
 }
 
 type Templates []Template
 
 type SentSMS struct {
-	ID           string  `json:"id"`
-	URI          string  `json:"uri"`
-	Reference    *string `json:"reference"`
-	ScheduledFor *string `json:"scheduled_for"`
+	ID           string     `json:"id"`
+	URI          string     `json:"uri"`
+	Reference    *string    `json:"reference"`
+	ScheduledFor *time.Time `json:"scheduled_for"`
 
 	Content struct {
 		Body       string `json:"body"`
@@ -204,10 +424,10 @@ type SentSMS struct {
 }
 
 type SentEmail struct {
-	ID           string  `json:"id"`
-	URI          string  `json:"uri"`
-	Reference    *string `json:"reference"`
-	ScheduledFor *string `json:"scheduled_for"`
+	ID           string     `json:"id"`
+	URI          string     `json:"uri"`
+	Reference    *string    `json:"reference"`
+	ScheduledFor *time.Time `json:"scheduled_for"`
 
 	Content struct {
 		Subject   string `json:"subject"`
@@ -223,26 +443,51 @@ type SentEmail struct {
 }
 
 type TemplatePreview struct {
-	ID      string `json:"id,omitempty"`
-	Type    string `json:"type"`
-	Version int    `json:"version"`
-	Subject string `json:"subject,omitempty"`
-	Body    string `json:"body"`
+	ID      string       `json:"id,omitempty"`
+	Type    TemplateType `json:"type"`
+	Version int          `json:"version"`
+	Subject string       `json:"subject,omitempty"`
+	Body    string       `json:"body"`
 }
 
+// Notification is a notification sent by your service. Fields which do not
+// apply to the notification's type, such as the address of an email, are
+// empty.
 type Notification struct {
-	ID           string `json:"id,omitempty"`
-	Subject      string `json:"subject"`
-	Body         string `json:"body"`
-	Reference    string `json:"reference"`
-	EmailAddress string `json:"email_address"`
-	PhoneNumber  string `json:"phone_number"`
-	Type         string `json:"type"`
-	Status       string `json:"status"`
-	CreatedBy    string `json:"created_by_name"`
-	CreatedAt    string `json:"created_at"`
-	SentAt       string `json:"sent_at"`
-	Template     struct {
+	ID           string             `json:"id,omitempty"`
+	Subject      string             `json:"subject"`
+	Body         string             `json:"body"`
+	Reference    string             `json:"reference"`
+	EmailAddress string             `json:"email_address"`
+	PhoneNumber  string             `json:"phone_number"`
+	Type         TemplateType       `json:"type"`
+	Status       NotificationStatus `json:"status"`
+
+	// Line1 to Line7 are the lines of a letter's address, and Postcode is
+	// the postcode when it was given separately to the address lines.
+	Line1    string       `json:"line_1"`
+	Line2    string       `json:"line_2"`
+	Line3    string       `json:"line_3"`
+	Line4    string       `json:"line_4"`
+	Line5    string       `json:"line_5"`
+	Line6    string       `json:"line_6"`
+	Line7    string       `json:"line_7"`
+	Postcode string       `json:"postcode"`
+	Postage  PostageClass `json:"postage"`
+
+	// CreatedBy is the name of the team member who sent the notification from
+	// the Notify website, and is empty for notifications sent with the API.
+	CreatedBy         string     `json:"created_by_name"`
+	CreatedAt         time.Time  `json:"created_at"`
+	SentAt            *time.Time `json:"sent_at"`
+	CompletedAt       *time.Time `json:"completed_at"`
+	ScheduledFor      *time.Time `json:"scheduled_for"`
+	EstimatedDelivery *time.Time `json:"estimated_delivery"`
+
+	CostInPennies float64 `json:"cost_in_pennies"`
+	BillableUnits int     `json:"billable_units"`
+
+	Template struct {
 		ID      string `json:"id"`
 		URI     string `json:"uri"`
 		Version int    `json:"version"`
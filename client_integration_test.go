@@ -141,7 +141,7 @@ func TestGetTemplateByID(t *testing.T) {
 	if resp.Name != wantedName {
 		t.Errorf("got %v, want %v", resp.Name, wantedName)
 	}
-	wantedType := "sms"
+	wantedType := notify.TemplateTypeSMS
 	if resp.Type != wantedType {
 		t.Errorf("got %v, want %v", resp.Type, wantedType)
 	}
@@ -158,7 +158,7 @@ func TestGetTemplateByIDAndVersion(t *testing.T) {
 	if resp.Name != wantedName {
 		t.Errorf("got %v, want %v", resp.Name, wantedName)
 	}
-	wantedType := "email"
+	wantedType := notify.TemplateTypeEmail
 	if resp.Type != wantedType {
 		t.Errorf("got %v, want %v", resp.Type, wantedType)
 	}
@@ -185,7 +185,7 @@ func TestGenerateTemplatePreview(t *testing.T) {
 	if resp.Body != wantedBody {
 		t.Errorf("got %v, want %v", resp.Body, wantedBody)
 	}
-	wantedType := "email"
+	wantedType := notify.TemplateTypeEmail
 	if resp.Type != wantedType {
 		t.Errorf("got %v, want %v", resp.Type, wantedType)
 	}
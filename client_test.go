@@ -2,16 +2,27 @@ package notify_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/notifyapi"
+	"github.com/govau/notify-client-go/notifytest"
 )
 
+const testAPIKey = "key_name-95b3b534-bdd6-4f26-ad91-84b4e2301cca-e8a5f59a-b445-4dc0-9513-c5831615f937"
+
 func TestNewClientAPIKey(t *testing.T) {
 	type args struct {
 	}
@@ -110,3 +121,1077 @@ func TestNewClientAndSend(t *testing.T) {
 		}
 	}
 }
+
+func TestSendSMSContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprintln(w, "{}")
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.SendSMSContext(ctx, "83f8a64f-74ec-4d90-ae48-394a8af3fe7c", "0400000000")
+	if err == nil {
+		t.Fatal("expected an error from a cancelled context")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("got context error %v, want %v", ctx.Err(), context.DeadlineExceeded)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		disableRetry bool
+		wantCalls    int
+		wantErr      bool
+	}{
+		{
+			name:      "rate limited is retried",
+			status:    http.StatusTooManyRequests,
+			wantCalls: 3,
+		},
+		{
+			name:      "unavailable is retried",
+			status:    http.StatusServiceUnavailable,
+			wantCalls: 3,
+		},
+		{
+			name:      "send is not retried on internal server error",
+			status:    http.StatusInternalServerError,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:         "retry disabled for the call",
+			status:       http.StatusTooManyRequests,
+			disableRetry: true,
+			wantCalls:    1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			var bodies []string
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var buf bytes.Buffer
+				io.Copy(&buf, r.Body)
+				bodies = append(bodies, buf.String())
+
+				calls++
+				if calls < 3 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tt.status)
+					fmt.Fprintf(w, `{"status_code": %d, "errors": [{"error": "Error", "message": "try again"}]}`, tt.status)
+					return
+				}
+				fmt.Fprintln(w, "{}")
+			}))
+			defer ts.Close()
+
+			client, err := notify.NewClient(
+				testAPIKey,
+				notify.WithBaseURL(ts.URL),
+				notify.WithRetryPolicy(notify.RetryPolicy{
+					MaxAttempts: 3,
+					MinBackoff:  time.Millisecond,
+					MaxBackoff:  time.Millisecond,
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			if tt.disableRetry {
+				ctx = notify.WithoutRetry(ctx)
+			}
+
+			_, err = client.SendSMSContext(ctx, "83f8a64f-74ec-4d90-ae48-394a8af3fe7c", "0400000000")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SendSMSContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+			for _, body := range bodies {
+				if !strings.Contains(body, "phone_number") {
+					t.Errorf("request body was not resent: %q", body)
+				}
+			}
+		})
+	}
+}
+
+func TestNotificationsIterator(t *testing.T) {
+	var queries []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/notifications" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		queries = append(queries, r.URL.RawQuery)
+
+		switch r.URL.Query().Get("older_than") {
+		case "":
+			fmt.Fprintf(w, `{
+				"notifications": [{"id": "3"}, {"id": "2"}],
+				"links": {"current": "%[1]s/v2/notifications", "next": "%[1]s/v2/notifications?older_than=2"}
+			}`, "http://"+r.Host)
+		case "2":
+			fmt.Fprintln(w, `{"notifications": [{"id": "1"}], "links": {"current": "x"}}`)
+		default:
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := client.Notifications(
+		context.Background(),
+		notify.FilterByTemplateType("sms"),
+		notify.FilterByStatus("delivered", "sending"),
+	)
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Notification().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := strings.Join(ids, ","), "3,2,1"; got != want {
+		t.Errorf("got ids %s, want %s", got, want)
+	}
+
+	wantQueries := []string{
+		"status=delivered&status=sending&template_type=sms",
+		"older_than=2&status=delivered&status=sending&template_type=sms",
+	}
+	if got, want := strings.Join(queries, " "), strings.Join(wantQueries, " "); got != want {
+		t.Errorf("got queries %s, want %s", got, want)
+	}
+}
+
+func TestSendLetter(t *testing.T) {
+	c := make(chan map[string]interface{}, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		c <- body
+
+		fmt.Fprintln(w, `{"id": "740e5834-3a29-46b4-9a6f-16142fde533a"}`)
+	}))
+	defer ts.Close()
+
+	client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.SendLetter(
+		"83f8a64f-74ec-4d90-ae48-394a8af3fe7c",
+		notify.Address{"Sam Smith", "1 Example Street", "Canberra ACT 2600"},
+		notify.Personalisation{{"name", "Sam"}},
+		notify.Reference("your-local-identifier"),
+		notify.Postage(notify.SecondClass),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := <-c
+	personalisation, _ := request["personalisation"].(map[string]interface{})
+	for key, want := range map[string]string{
+		"name":           "Sam",
+		"address_line_1": "Sam Smith",
+		"address_line_3": "Canberra ACT 2600",
+	} {
+		if got := personalisation[key]; got != want {
+			t.Errorf("personalisation %s: got %v, want %v", key, got, want)
+		}
+	}
+	if got, want := request["postage"], "second"; got != want {
+		t.Errorf("postage: got %v, want %v", got, want)
+	}
+
+	_, err = client.SendLetter("83f8a64f-74ec-4d90-ae48-394a8af3fe7c", notify.Address{"Sam Smith"})
+	if err == nil {
+		t.Error("expected an error for a short address")
+	}
+}
+
+func TestSendPrecompiledLetter(t *testing.T) {
+	c := make(chan map[string]interface{}, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		c <- body
+
+		fmt.Fprintln(w, `{"id": "740e5834-3a29-46b4-9a6f-16142fde533a", "reference": "letter-1", "postage": "first"}`)
+	}))
+	defer ts.Close()
+
+	client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.SendPrecompiledLetter("letter-1", strings.NewReader("%PDF-1.4"), notify.Postage(notify.FirstClass))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Postage != notify.FirstClass {
+		t.Errorf("got postage %v, want %v", resp.Postage, notify.FirstClass)
+	}
+
+	request := <-c
+	if got, want := request["content"], "JVBERi0xLjQ="; got != want {
+		t.Errorf("content: got %v, want %v", got, want)
+	}
+	if got, want := request["reference"], "letter-1"; got != want {
+		t.Errorf("reference: got %v, want %v", got, want)
+	}
+}
+
+func TestGetLetterPDF(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/notifications/740e5834-3a29-46b4-9a6f-16142fde533a/pdf" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		fmt.Fprint(w, "%PDF-1.4")
+	}))
+	defer ts.Close()
+
+	client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pdf, err := client.GetLetterPDF("740e5834-3a29-46b4-9a6f-16142fde533a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pdf) != "%PDF-1.4" {
+		t.Errorf("got %q, want %q", pdf, "%PDF-1.4")
+	}
+}
+
+func TestReceivedTextsIterator(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/received-text-messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		switch r.URL.Query().Get("older_than") {
+		case "":
+			fmt.Fprintln(w, `{
+				"received_text_messages": [
+					{"id": "b", "user_number": "61400000000", "notify_number": "61411111111", "content": "STOP"}
+				],
+				"links": {"current": "x", "next": "/v2/received-text-messages?older_than=b"}
+			}`)
+		case "b":
+			fmt.Fprintln(w, `{"received_text_messages": [{"id": "a", "content": "Yes"}], "links": {"current": "x"}}`)
+		default:
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := client.ReceivedTexts(context.Background())
+
+	var contents []string
+	for it.Next() {
+		contents = append(contents, it.ReceivedText().Content)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := strings.Join(contents, ","), "STOP,Yes"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestPrepareUpload(t *testing.T) {
+	upload, err := notify.PrepareUpload(
+		strings.NewReader("name,amount\nSam,205.20\n"),
+		notify.IsCSV(true),
+		notify.Filename("report.csv"),
+		notify.ConfirmEmailBeforeDownload(false),
+		notify.RetentionPeriod(4),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json.Marshal(upload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"file":"bmFtZSxhbW91bnQKU2FtLDIwNS4yMAo=","is_csv":true,"filename":"report.csv","confirm_email_before_download":false,"retention_period":"4 weeks"}`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	_, err = notify.PrepareUpload(bytes.NewReader(make([]byte, notify.MaxUploadSize+1)))
+	if err != notify.ErrFileTooLarge {
+		t.Errorf("got error %v, want %v", err, notify.ErrFileTooLarge)
+	}
+
+	_, err = notify.PrepareUpload(strings.NewReader("x"), notify.RetentionPeriod(79))
+	if err == nil {
+		t.Error("expected an error for a retention period over 78 weeks")
+	}
+}
+
+func TestWatchNotification(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Hello"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := client.SendSMS(template.ID, "0400000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statuses []string
+	final, err := client.WatchNotification(
+		context.Background(),
+		sent.ID,
+		func(n notify.Notification) {
+			statuses = append(statuses, string(n.Status))
+
+			if next, ok := map[notify.NotificationStatus]notify.NotificationStatus{
+				notify.StatusCreated: notify.StatusSending,
+				notify.StatusSending: notify.StatusDelivered,
+			}[n.Status]; ok {
+				if err := server.SetStatus(n.ID, next); err != nil {
+					t.Error(err)
+				}
+			}
+		},
+		notify.PollInterval(time.Millisecond, 5*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if final.Status != notify.StatusDelivered {
+		t.Errorf("got final status %s, want delivered", final.Status)
+	}
+	if final.SentAt == nil || final.SentAt.Before(final.CreatedAt) {
+		t.Errorf("got sent at %v, want a time after %v", final.SentAt, final.CreatedAt)
+	}
+	if got, want := strings.Join(statuses, ","), "created,sending,delivered"; got != want {
+		t.Errorf("got statuses %s, want %s", got, want)
+	}
+}
+
+func TestPersonalisationValidation(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{
+		Type:    "email",
+		Subject: "Your ((service)) appointment",
+		Body:    "Hi ((first name)), see you on ((day)).",
+	})
+
+	client, err := server.Client(notify.WithPersonalisationValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.SendEmail(template.ID, "someone@example.com", notify.Personalisation{
+		{"First_Name", "Kim"},
+		{"day", "Friday"},
+		{"colour", "blue"},
+	})
+	personalisationErr, ok := err.(notify.PersonalisationError)
+	if !ok {
+		t.Fatalf("got error %v, want a PersonalisationError", err)
+	}
+	if got, want := strings.Join(personalisationErr.Missing, ","), "service"; got != want {
+		t.Errorf("got missing %s, want %s", got, want)
+	}
+	if got, want := strings.Join(personalisationErr.Unexpected, ","), "colour"; got != want {
+		t.Errorf("got unexpected %s, want %s", got, want)
+	}
+	if got := server.Calls(notifytest.SendEmail); got != 0 {
+		t.Errorf("got %d sends, want none", got)
+	}
+
+	_, err = client.SendEmail(template.ID, "someone@example.com", notify.Personalisation{
+		{"first-name", "Kim"},
+		{"day", "Friday"},
+		{"service", "dental"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := server.Calls(notifytest.GetTemplate); got != 1 {
+		t.Errorf("got %d template fetches, want 1", got)
+	}
+}
+
+func TestTemplateCache(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Version one"})
+
+	client, err := server.Client(notify.WithTemplateCache(50 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetTemplateByID(template.ID); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	server.AddTemplate(notify.Template{ID: template.ID, Type: "sms", Body: "Version two"})
+
+	cached, err := client.GetTemplateByID(template.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Body != "Version one" {
+		t.Errorf("got %q, want the cached template", cached.Body)
+	}
+
+	client.InvalidateTemplate(template.ID)
+	latest, err := client.GetTemplateByID(template.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Body != "Version two" {
+		t.Errorf("got %q after invalidating, want the latest template", latest.Body)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetTemplateByIDAndVersion(template.ID, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetAllTemplates("sms"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := server.Calls(notifytest.GetTemplateVersion); got != 1 {
+		t.Errorf("got %d version fetches, want 1", got)
+	}
+	if got := server.Calls(notifytest.GetTemplates); got != 1 {
+		t.Errorf("got %d template list fetches, want 1", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := client.GetAllTemplates("sms"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTemplateByIDAndVersion(template.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.Calls(notifytest.GetTemplates); got != 2 {
+		t.Errorf("got %d template list fetches after the ttl, want 2", got)
+	}
+	if got := server.Calls(notifytest.GetTemplateVersion); got != 1 {
+		t.Errorf("got %d version fetches after the ttl, want 1", got)
+	}
+
+	if _, err := server.Client(notify.WithTemplateCache(0)); err == nil {
+		t.Error("expected an error for a zero ttl")
+	}
+}
+
+func TestNonJSONErrorResponse(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Hello"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := "<html><body><h1>502 Bad Gateway</h1>" + strings.Repeat("padding ", 200) + "</body></html>"
+	server.Inject(notifytest.Scenario{
+		Endpoint: notifytest.SendSMS,
+		Fault:    notifytest.ServerError(http.StatusBadGateway, page),
+	})
+
+	_, err = client.SendSMS(template.ID, "0400000000")
+
+	var apiErr *notifyapi.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got error %v, want a notifyapi.Error", err)
+	}
+	if apiErr.Code != http.StatusBadGateway {
+		t.Errorf("got code %d, want %d", apiErr.Code, http.StatusBadGateway)
+	}
+	if got := apiErr.Header.Get("Content-Type"); got != "text/html" {
+		t.Errorf("got content type %q, want text/html", got)
+	}
+	if !strings.HasPrefix(apiErr.Body, "<html><body><h1>502 Bad Gateway</h1>") {
+		t.Errorf("body %q does not contain the response", apiErr.Body)
+	}
+	if len(apiErr.Body) > 520 {
+		t.Errorf("body was not truncated, got %d bytes", len(apiErr.Body))
+	}
+	if !errors.Is(err, notifyapi.ErrServer) {
+		t.Errorf("errors.Is(%v, ErrServer) = false", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestHTTPClientOptions(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Hello"})
+
+	var proxied []string
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		proxied = append(proxied, req.URL.Path)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	for name, option := range map[string]notify.ClientOption{
+		"WithTransport":  notify.WithTransport(transport),
+		"WithHTTPClient": notify.WithHTTPClient(&http.Client{Transport: transport}),
+	} {
+		proxied = nil
+
+		client, err := server.Client(option)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetTemplateByID(template.ID); err != nil {
+			t.Fatal(err)
+		}
+		if len(proxied) != 1 || proxied[0] != "/v2/template/"+template.ID {
+			t.Errorf("%s: request was not sent through the transport, got %v", name, proxied)
+		}
+	}
+
+	client, err := server.Client(notify.WithTimeout(20 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Inject(notifytest.Scenario{
+		Endpoint: notifytest.GetTemplate,
+		Fault:    notifytest.Timeout(),
+	})
+	if _, err := client.GetTemplateByID(template.ID); err == nil {
+		t.Error("expected the request to time out")
+	}
+}
+
+func TestRouteSecret(t *testing.T) {
+	headers := make(chan http.Header, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+		fmt.Fprintln(w, "{}")
+	}))
+	defer ts.Close()
+
+	for _, secret := range []string{"", "s3cret"} {
+		client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL), notify.WithRouteSecret(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetNotificationById("740e5834-3a29-46b4-9a6f-16142fde533a"); err != nil {
+			t.Fatal(err)
+		}
+
+		header := <-headers
+		values, ok := header["X-Custom-Forwarder"]
+		if secret == "" && ok {
+			t.Errorf("got X-Custom-Forwarder %v, want it omitted", values)
+		}
+		if secret != "" && header.Get("X-Custom-Forwarder") != secret {
+			t.Errorf("got X-Custom-Forwarder %v, want %s", values, secret)
+		}
+	}
+}
+
+func TestNotificationStatus(t *testing.T) {
+	tests := []struct {
+		status      notify.NotificationStatus
+		wantFinal   bool
+		wantFailure bool
+	}{
+		{notify.StatusCreated, false, false},
+		{notify.StatusSending, false, false},
+		{notify.StatusPending, false, false},
+		{notify.StatusSent, true, false},
+		{notify.StatusDelivered, true, false},
+		{notify.StatusPermanentFailure, true, true},
+		{notify.StatusTemporaryFailure, true, true},
+		{notify.StatusTechnicalFailure, true, true},
+		{notify.StatusPendingVirusCheck, false, false},
+		{notify.StatusVirusScanFailed, true, true},
+		{notify.StatusValidationFailed, true, true},
+		{notify.StatusAccepted, false, false},
+		{notify.StatusReceived, true, false},
+		{notify.StatusCancelled, true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsFinal(); got != tt.wantFinal {
+			t.Errorf("%s: got IsFinal %v, want %v", tt.status, got, tt.wantFinal)
+		}
+		if got := tt.status.IsFailure(); got != tt.wantFailure {
+			t.Errorf("%s: got IsFailure %v, want %v", tt.status, got, tt.wantFailure)
+		}
+	}
+}
+
+func TestNotificationFixtures(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min, sec, usec int) *time.Time {
+		t := time.Date(year, month, day, hour, min, sec, usec*1000, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		file  string
+		check func(t *testing.T, n notify.Notification)
+	}{
+		{
+			file: "notification_email.json",
+			check: func(t *testing.T, n notify.Notification) {
+				if n.Type != notify.TemplateTypeEmail || n.Status != notify.StatusDelivered {
+					t.Errorf("got type %s and status %s", n.Type, n.Status)
+				}
+				if n.EmailAddress != "someone@example.gov.au" || n.PhoneNumber != "" {
+					t.Errorf("got email address %q and phone number %q", n.EmailAddress, n.PhoneNumber)
+				}
+				if n.Reference != "your-reference" || n.Subject != "Application approved" {
+					t.Errorf("got reference %q and subject %q", n.Reference, n.Subject)
+				}
+				if want := date(2019, 5, 1, 1, 2, 3, 123456); !n.CreatedAt.Equal(*want) {
+					t.Errorf("got created at %v, want %v", n.CreatedAt, want)
+				}
+				if want := date(2019, 5, 1, 1, 2, 9, 500000); n.CompletedAt == nil || !n.CompletedAt.Equal(*want) {
+					t.Errorf("got completed at %v, want %v", n.CompletedAt, want)
+				}
+				if n.ScheduledFor != nil || n.EstimatedDelivery != nil {
+					t.Errorf("got scheduled for %v and estimated delivery %v, want nil", n.ScheduledFor, n.EstimatedDelivery)
+				}
+				if n.Template.Version != 3 {
+					t.Errorf("got template version %d, want 3", n.Template.Version)
+				}
+				if len(n.Address()) != 0 {
+					t.Errorf("got address %v, want none", n.Address())
+				}
+			},
+		},
+		{
+			file: "notification_sms.json",
+			check: func(t *testing.T, n notify.Notification) {
+				if n.Type != notify.TemplateTypeSMS || n.Status != notify.StatusPermanentFailure {
+					t.Errorf("got type %s and status %s", n.Type, n.Status)
+				}
+				if n.PhoneNumber != "+61400000000" || n.Reference != "" {
+					t.Errorf("got phone number %q and reference %q", n.PhoneNumber, n.Reference)
+				}
+				if n.CreatedBy != "Sam Citizen" {
+					t.Errorf("got created by %q", n.CreatedBy)
+				}
+				if want := date(2019, 5, 2, 9, 59, 0, 0); n.ScheduledFor == nil || !n.ScheduledFor.Equal(*want) {
+					t.Errorf("got scheduled for %v, want %v", n.ScheduledFor, want)
+				}
+				if n.CostInPennies != 5.4 || n.BillableUnits != 2 {
+					t.Errorf("got cost %v for %d units", n.CostInPennies, n.BillableUnits)
+				}
+			},
+		},
+		{
+			file: "notification_letter.json",
+			check: func(t *testing.T, n notify.Notification) {
+				if n.Type != notify.TemplateTypeLetter || n.Status != notify.StatusReceived {
+					t.Errorf("got type %s and status %s", n.Type, n.Status)
+				}
+				if got, want := strings.Join(n.Address(), "|"), "Kim Citizen|1 Example Street|Canberra ACT|2600"; got != want {
+					t.Errorf("got address %s, want %s", got, want)
+				}
+				if n.Postage != notify.SecondClass {
+					t.Errorf("got postage %s, want second", n.Postage)
+				}
+				if want := date(2019, 5, 8, 6, 0, 0, 0); n.EstimatedDelivery == nil || !n.EstimatedDelivery.Equal(*want) {
+					t.Errorf("got estimated delivery %v, want %v", n.EstimatedDelivery, want)
+				}
+				if n.CostInPennies != 76 || n.BillableUnits != 1 {
+					t.Errorf("got cost %v for %d units", n.CostInPennies, n.BillableUnits)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			fixture, err := ioutil.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(fixture)
+			}))
+			defer ts.Close()
+
+			client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			n, err := client.GetNotificationById("id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, n)
+		})
+	}
+}
+
+func TestScheduledFor(t *testing.T) {
+	var requests int
+	var scheduledFor string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var body struct {
+			ScheduledFor string `json:"scheduled_for"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		scheduledFor = body.ScheduledFor
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "740e5834-3a29-46b4-9a6f-16142fde533a", "scheduled_for": "2019-05-01 03:30"}`)
+	}))
+	defer ts.Close()
+
+	client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	location, err := time.LoadLocation(notify.ScheduleTimeZone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	when := time.Now().Add(time.Hour)
+	sent, err := client.SendSMS("template", "0400000000", notify.ScheduledFor(when))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := when.In(location).Format(notify.ScheduledForLayout); scheduledFor != want {
+		t.Errorf("got scheduled_for %q, want %q", scheduledFor, want)
+	}
+	if want := time.Date(2019, 5, 1, 3, 30, 0, 0, location); sent.ScheduledFor == nil || !sent.ScheduledFor.Equal(want) {
+		t.Errorf("got scheduled for %v, want %v", sent.ScheduledFor, want)
+	}
+
+	for _, when := range []time.Time{
+		time.Now().Add(-time.Minute),
+		// Truncated to the minute, which is in the past.
+		time.Now().Add(time.Nanosecond),
+		time.Now().Add(notify.MaxSchedule + time.Minute),
+	} {
+		if _, err := client.SendEmail("template", "someone@example.gov.au", notify.ScheduledFor(when)); err == nil {
+			t.Errorf("%v: expected an error", when)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestBulkSender(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maxIn    int
+	)
+	mock := &notifytest.Mock{
+		SendSMSFunc: func(ctx context.Context, id, phoneNumber string, options ...notify.SendSMSOption) (notify.SentSMS, error) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxIn {
+				maxIn = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(2 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+
+			if phoneNumber == "invalid" {
+				return notify.SentSMS{}, errors.New("invalid phone number")
+			}
+			return notify.SentSMS{ID: "id-" + phoneNumber}, nil
+		},
+	}
+
+	recipients := make(chan notify.BulkRecipient)
+	go func() {
+		defer close(recipients)
+		for i := 0; i < 20; i++ {
+			to := fmt.Sprintf("04000000%02d", i)
+			if i == 7 {
+				to = "invalid"
+			}
+			recipients <- notify.BulkRecipient{
+				To:              to,
+				Personalisation: notify.Personalisation{{"index", i}},
+				Reference:       fmt.Sprint(i),
+			}
+		}
+	}()
+
+	sender, err := notify.NewBulkSender(mock, notify.BulkConcurrency(3), notify.BulkRateLimit(10, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[int]bool{}
+	stats, err := sender.SendSMS(context.Background(), "template", recipients, func(r notify.BulkResult) {
+		seen[r.Index] = true
+		if r.Index == 7 {
+			if r.Err == nil {
+				t.Error("expected an error for the invalid recipient")
+			}
+		} else if r.Err != nil || r.ID != "id-"+r.Recipient.To {
+			t.Errorf("%d: got ID %q and error %v", r.Index, r.ID, r.Err)
+		}
+	}, notify.SMSSenderID("sender"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Sent != 19 || stats.Failed != 1 {
+		t.Errorf("got %d sent and %d failed, want 19 and 1", stats.Sent, stats.Failed)
+	}
+	if len(seen) != 20 {
+		t.Errorf("got %d results, want 20", len(seen))
+	}
+	if maxIn > 3 {
+		t.Errorf("got %d concurrent sends, want at most 3", maxIn)
+	}
+	// The first 10 messages use the initial burst, and the other 10 wait for
+	// the bucket to refill.
+	if stats.Elapsed < 80*time.Millisecond {
+		t.Errorf("sent 20 messages in %v, want the rate limit to slow them down", stats.Elapsed)
+	}
+
+	calls := mock.CallsTo("SendSMS")
+	if len(calls) != 20 {
+		t.Fatalf("got %d calls, want 20", len(calls))
+	}
+	if options := calls[0].Args[2].([]notify.SendSMSOption); len(options) != 3 {
+		t.Errorf("got %d options, want personalisation, reference and sender ID", len(options))
+	}
+}
+
+func TestBulkSenderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mock := &notifytest.Mock{}
+	recipients := make(chan notify.BulkRecipient)
+	go func() {
+		recipients <- notify.BulkRecipient{To: "0400000000"}
+		cancel()
+	}()
+
+	sender, err := notify.NewBulkSender(mock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := sender.SendSMS(ctx, "template", recipients, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+	if stats.Sent+stats.Failed != 1 {
+		t.Errorf("got %+v, want one message", stats)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": "740e5834-3a29-46b4-9a6f-16142fde533a"}`)
+	}))
+	defer ts.Close()
+
+	client, err := notify.NewClient(testAPIKey, notify.WithBaseURL(ts.URL), notify.WithRateLimit(5, 100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetNotificationById("id"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The first 5 requests use the initial burst, and the other 5 wait for the
+	// bucket to refill.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("made 10 requests in %v, want the rate limit to slow them down", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetNotificationByIdContext(ctx, "id"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if requests != 10 {
+		t.Errorf("got %d requests, want 10", requests)
+	}
+
+	if _, err := notify.NewClient(testAPIKey, notify.WithKeyTypeRateLimit("unknown")); err == nil {
+		t.Error("expected an error for an unknown key type")
+	}
+	if _, err := notify.NewClient(testAPIKey, notify.WithKeyTypeRateLimit(notify.LiveKey)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWatchNotificationMinInterval(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Hello"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, err := client.SendSMS(template.ID, "0400000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.WatchNotification(ctx, sent.ID, nil, notify.PollInterval(0, 0))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if checks := server.Calls(notifytest.GetNotification); checks > 10 {
+		t.Errorf("got %d checks in 50ms with a zero interval, want the interval to be clamped", checks)
+	}
+}
+
+func TestPersonalisationValidationUsesTemplateCache(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Hello ((name))"})
+
+	client, err := server.Client(
+		notify.WithPersonalisationValidation(),
+		notify.WithTemplateCache(20*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.SendSMS(template.ID, "0400000000", notify.Personalisation{{"name", "Kim"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.AddTemplate(notify.Template{ID: template.ID, Type: "sms", Body: "Hello ((name)), your code is ((code))"})
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = client.SendSMS(template.ID, "0400000000", notify.Personalisation{{"name", "Kim"}})
+	personalisationErr, ok := err.(notify.PersonalisationError)
+	if !ok || strings.Join(personalisationErr.Missing, ",") != "code" {
+		t.Errorf("got error %v, want code to be missing from the new version", err)
+	}
+}
+
+func TestBulkSenderOptions(t *testing.T) {
+	for _, option := range []notify.BulkOption{
+		notify.BulkConcurrency(0),
+		notify.BulkRateLimit(0, time.Minute),
+		notify.BulkRateLimit(3000, 0),
+	} {
+		if _, err := notify.NewBulkSender(&notifytest.Mock{}, option); err == nil {
+			t.Error("expected an error for an invalid option")
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	notify "github.com/govau/notify-client-go"
+)
+
+// personalisationFlags collects personalisation from repeated -p key=value
+// flags and a -personalisation JSON object.
+type personalisationFlags struct {
+	values map[string]interface{}
+}
+
+func addPersonalisationFlags(flags *flag.FlagSet) *personalisationFlags {
+	p := &personalisationFlags{values: map[string]interface{}{}}
+	flags.Var(keyValueFlag(p.values), "p", "personalisation as `key=value`, may be repeated")
+	flags.Var(jsonFlag(p.values), "personalisation", "personalisation as a `JSON` object")
+	return p
+}
+
+func (p *personalisationFlags) personalisation() notify.Personalisation {
+	var keys []string
+	for key := range p.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var personalisation notify.Personalisation
+	for _, key := range keys {
+		personalisation = append(personalisation, struct {
+			Key   string
+			Value interface{}
+		}{key, p.values[key]})
+	}
+	return personalisation
+}
+
+type keyValueFlag map[string]interface{}
+
+func (f keyValueFlag) String() string {
+	return ""
+}
+
+func (f keyValueFlag) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i < 1 {
+		return fmt.Errorf("%q is not in the form key=value", value)
+	}
+	f[value[:i]] = value[i+1:]
+	return nil
+}
+
+type jsonFlag map[string]interface{}
+
+func (f jsonFlag) String() string {
+	return ""
+}
+
+func (f jsonFlag) Set(value string) error {
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return fmt.Errorf("personalisation is not a JSON object: %v", err)
+	}
+	for k, v := range values {
+		f[k] = v
+	}
+	return nil
+}
+
+// parseFlags parses the flags of a command. The flag package reports any
+// errors, so they are returned as errUsage.
+func (env *environment) parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(env.stderr)
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+func sendEmail(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("send-email", flag.ContinueOnError)
+	templateID := flags.String("template", "", "template ID")
+	to := flags.String("to", "", "email address to send to")
+	reference := flags.String("reference", "", "reference to identify the notification")
+	replyTo := flags.String("reply-to", "", "ID of the reply-to email address")
+	personalisation := addPersonalisationFlags(flags)
+
+	if err := env.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *templateID == "" || *to == "" || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	options := []notify.SendEmailOption{personalisation.personalisation()}
+	if *reference != "" {
+		options = append(options, notify.Reference(*reference))
+	}
+	if *replyTo != "" {
+		options = append(options, notify.EmailReplyToID(*replyTo))
+	}
+
+	sent, err := env.client.SendEmailContext(ctx, *templateID, *to, options...)
+	if err != nil {
+		return err
+	}
+
+	return env.print(sent, [][2]string{
+		{"ID", sent.ID},
+		{"Subject", sent.Content.Subject},
+		{"Body", sent.Content.Body},
+		{"From", sent.Content.FromEmail},
+	})
+}
+
+func sendSMS(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("send-sms", flag.ContinueOnError)
+	templateID := flags.String("template", "", "template ID")
+	to := flags.String("to", "", "phone number to send to")
+	reference := flags.String("reference", "", "reference to identify the notification")
+	sender := flags.String("sender", "", "ID of the SMS sender")
+	personalisation := addPersonalisationFlags(flags)
+
+	if err := env.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *templateID == "" || *to == "" || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	options := []notify.SendSMSOption{personalisation.personalisation()}
+	if *reference != "" {
+		options = append(options, notify.Reference(*reference))
+	}
+	if *sender != "" {
+		options = append(options, notify.SMSSenderID(*sender))
+	}
+
+	sent, err := env.client.SendSMSContext(ctx, *templateID, *to, options...)
+	if err != nil {
+		return err
+	}
+
+	return env.print(sent, [][2]string{
+		{"ID", sent.ID},
+		{"Body", sent.Content.Body},
+		{"From", sent.Content.FromNumber},
+	})
+}
+
+func listTemplates(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("templates list", flag.ContinueOnError)
+	typ := flags.String("type", "", "only list templates of this type")
+
+	if err := env.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+
+	templates, err := env.client.GetAllTemplatesContext(ctx, notify.TemplateType(*typ))
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{{"ID", "TYPE", "VERSION", "NAME"}}
+	for _, t := range templates {
+		rows = append(rows, []string{t.ID, string(t.Type), strconv.Itoa(t.Version), t.Name})
+	}
+	return env.printTable(templates, rows)
+}
+
+func getTemplate(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("templates get", flag.ContinueOnError)
+	version := flags.Int("version", 0, "get this version of the template instead of the latest")
+
+	if err := env.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	var template notify.Template
+	var err error
+	if *version > 0 {
+		template, err = env.client.GetTemplateByIDAndVersionContext(ctx, flags.Arg(0), *version)
+	} else {
+		template, err = env.client.GetTemplateByIDContext(ctx, flags.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	return env.print(template, [][2]string{
+		{"ID", template.ID},
+		{"Name", template.Name},
+		{"Type", string(template.Type)},
+		{"Version", strconv.Itoa(template.Version)},
+		{"Created", formatTime(&template.CreatedAt)},
+		{"Subject", template.Subject},
+		{"Body", template.Body},
+	})
+}
+
+func previewTemplate(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("preview", flag.ContinueOnError)
+	personalisation := addPersonalisationFlags(flags)
+
+	if err := env.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	preview, err := env.client.GenerateTemplatePreviewContext(ctx, flags.Arg(0), personalisation.personalisation())
+	if err != nil {
+		return err
+	}
+
+	return env.print(preview, [][2]string{
+		{"ID", preview.ID},
+		{"Type", string(preview.Type)},
+		{"Version", strconv.Itoa(preview.Version)},
+		{"Subject", preview.Subject},
+		{"Body", preview.Body},
+	})
+}
+
+func getNotification(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("notification get", flag.ContinueOnError)
+
+	if err := env.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	n, err := env.client.GetNotificationByIdContext(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return env.print(n, notificationFields(n))
+}
+
+func notificationFields(n notify.Notification) [][2]string {
+	recipient := n.EmailAddress
+	if recipient == "" {
+		recipient = n.PhoneNumber
+	}
+	if recipient == "" {
+		recipient = strings.Join(n.Address(), ", ")
+	}
+
+	return [][2]string{
+		{"ID", n.ID},
+		{"Type", string(n.Type)},
+		{"Status", string(n.Status)},
+		{"To", recipient},
+		{"Reference", n.Reference},
+		{"Template", n.Template.ID},
+		{"Created", formatTime(&n.CreatedAt)},
+		{"Sent", formatTime(n.SentAt)},
+		{"Completed", formatTime(n.CompletedAt)},
+		{"Subject", n.Subject},
+		{"Body", n.Body},
+	}
+}
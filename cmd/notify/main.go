@@ -0,0 +1,169 @@
+// Command notify sends and inspects notifications using the Notify API.
+//
+// Usage:
+//
+//	notify [flags] <command> [arguments]
+//
+// The commands are:
+//
+//	send-email         send an email
+//	send-sms           send a text message
+//	templates list     list templates
+//	templates get      get a template
+//	preview            preview a template with personalisation
+//	notification get   get a notification
+//	watch              follow a notification until it is delivered or fails
+//
+// watch exits with status 0 when the notification is delivered, 3, 4 or 5 for a
+// permanent, temporary or technical failure, and 6 for any other failure, such
+// as a letter which failed validation.
+//
+// The API key is read from the NOTIFY_API_KEY environment variable, or from the
+// file given with -api-key-file.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/internal/base"
+)
+
+const usage = `Usage: notify [flags] <command> [arguments]
+
+Commands:
+  send-email -template ID -to ADDRESS [-p key=value]...
+  send-sms -template ID -to NUMBER [-p key=value]...
+  templates list [-type email|sms|letter]
+  templates get [-version N] ID
+  preview [-p key=value]... ID
+  notification get ID
+  watch [-interval D] [-max-interval D] [-timeout D] ID
+
+Flags:
+`
+
+// errUsage is returned when a command is used incorrectly.
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	name string
+	run  func(ctx context.Context, env *environment, args []string) error
+}
+
+var commands = []command{
+	{"send-email", sendEmail},
+	{"send-sms", sendSMS},
+	{"templates list", listTemplates},
+	{"templates get", getTemplate},
+	{"preview", previewTemplate},
+	{"notification get", getNotification},
+	{"watch", watchNotification},
+}
+
+// environment is shared by every command.
+type environment struct {
+	client *notify.Client
+	out    io.Writer
+	stderr io.Writer
+	json   bool
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("notify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	apiKeyFile := flags.String("api-key-file", "", "read the API key from `file` instead of NOTIFY_API_KEY")
+	baseURL := flags.String("base-url", notifyBaseURL(), "base URL of the Notify API")
+	jsonOutput := flags.Bool("json", false, "print results as JSON")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cmd, cmdArgs, ok := findCommand(flags.Args())
+	if !ok {
+		flags.Usage()
+		return 2
+	}
+
+	apiKey, err := readAPIKey(*apiKeyFile)
+	if err != nil {
+		fmt.Fprintln(stderr, "notify:", err)
+		return 1
+	}
+
+	client, err := notify.NewClient(apiKey, notify.WithBaseURL(*baseURL))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	env := &environment{client: client, out: stdout, stderr: stderr, json: *jsonOutput}
+	if err := cmd.run(ctx, env, cmdArgs); err != nil {
+		if err == errUsage {
+			flags.Usage()
+			return 2
+		}
+		if code, ok := err.(exitCode); ok {
+			return int(code)
+		}
+		fmt.Fprintf(stderr, "notify %s: %v\n", cmd.name, err)
+		return 1
+	}
+
+	return 0
+}
+
+// findCommand returns the command named by the start of args, and the
+// remaining arguments.
+func findCommand(args []string) (command, []string, bool) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) < len(words) {
+			continue
+		}
+		if strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func notifyBaseURL() string {
+	if url := os.Getenv("NOTIFY_BASE_URL"); url != "" {
+		return url
+	}
+	return base.NotifyBaseURL
+}
+
+func readAPIKey(file string) (string, error) {
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	apiKey := os.Getenv("NOTIFY_API_KEY")
+	if apiKey == "" {
+		return "", errors.New("NOTIFY_API_KEY is not set and -api-key-file was not given")
+	}
+	return apiKey, nil
+}
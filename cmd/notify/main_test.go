@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/notifytest"
+)
+
+func TestRun(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{
+		Name: "reminder",
+		Type: "sms",
+		Body: "Hello ((name)), your appointment is on ((day)).",
+	})
+
+	os.Setenv("NOTIFY_API_KEY", server.APIKey)
+	defer os.Unsetenv("NOTIFY_API_KEY")
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{
+		"-base-url", server.URL,
+		"send-sms",
+		"-template", template.ID,
+		"-to", "0400000000",
+		"-p", "name=Kim",
+		"-personalisation", `{"day": "Friday"}`,
+	}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("send-sms exited with %d: %s", code, stderr.String())
+	}
+	if want := "Hello Kim, your appointment is on Friday."; !strings.Contains(stdout.String(), want) {
+		t.Errorf("output %q does not contain %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	code = run(context.Background(), []string{
+		"-base-url", server.URL,
+		"-json",
+		"templates", "list",
+	}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("templates list exited with %d: %s", code, stderr.String())
+	}
+	var templates notify.Templates
+	if err := json.Unmarshal(stdout.Bytes(), &templates); err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].Name != "reminder" {
+		t.Errorf("unexpected templates %+v", templates)
+	}
+
+	code = run(context.Background(), []string{"-base-url", server.URL, "send-sms", "-to", "0400000000"}, &stdout, &stderr)
+	if code != 2 {
+		t.Errorf("got exit code %d for a missing template, want 2", code)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Hello"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("NOTIFY_API_KEY", server.APIKey)
+	defer os.Unsetenv("NOTIFY_API_KEY")
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-base-url", server.URL, "watch", "-interval", "0", "id"}, &stdout, &stderr); code != 2 {
+		t.Errorf("got exit code %d for a zero interval, want 2", code)
+	}
+
+	tests := []struct {
+		status   notify.NotificationStatus
+		wantCode int
+	}{
+		{notify.StatusDelivered, 0},
+		{notify.StatusPermanentFailure, 3},
+		{notify.StatusValidationFailed, 6},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			sent, err := client.SendSMS(template.ID, "0400000000")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := server.SetStatus(sent.ID, tt.status); err != nil {
+				t.Fatal(err)
+			}
+
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), []string{"-base-url", server.URL, "watch", "-interval", "1ms", sent.ID}, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("got exit code %d, want %d: %s", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), string(tt.status)) {
+				t.Errorf("output %q does not contain the status", stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// print writes v as JSON, or fields as a two column table.
+func (env *environment) print(v interface{}, fields [][2]string) error {
+	if env.json {
+		return env.printJSON(v)
+	}
+
+	w := tabwriter.NewWriter(env.out, 0, 4, 2, ' ', 0)
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(field[1], "\n"), "\n")
+		fmt.Fprintf(w, "%s:\t%s\n", field[0], lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(w, "\t%s\n", line)
+		}
+	}
+	return w.Flush()
+}
+
+// printTable writes v as JSON, or rows as a table with the first row as the
+// header.
+func (env *environment) printTable(v interface{}, rows [][]string) error {
+	if env.json {
+		return env.printJSON(v)
+	}
+
+	w := tabwriter.NewWriter(env.out, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func (env *environment) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(env.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// formatTime formats t in the local time zone, or returns an empty string if t
+// is not set.
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
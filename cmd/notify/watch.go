@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	notify "github.com/govau/notify-client-go"
+)
+
+// exitCode is returned by a command to exit with a specific status without
+// printing an error.
+type exitCode int
+
+func (code exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(code))
+}
+
+// failureExitCode is the exit code used by watch for a failure status which is
+// not in statusExitCodes, such as a letter which failed validation.
+const failureExitCode exitCode = 6
+
+// statusExitCodes are the exit codes used by watch for each final status.
+var statusExitCodes = map[notify.NotificationStatus]exitCode{
+	notify.StatusDelivered:        0,
+	notify.StatusPermanentFailure: 3,
+	notify.StatusTemporaryFailure: 4,
+	notify.StatusTechnicalFailure: 5,
+}
+
+func watchNotification(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", time.Second, "initial time between checks")
+	maxInterval := flags.Duration("max-interval", 30*time.Second, "maximum time between checks")
+	timeout := flags.Duration("timeout", 0, "give up after this long, if set")
+
+	if err := env.parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+	if *interval <= 0 || *maxInterval <= 0 {
+		fmt.Fprintln(env.stderr, "watch: -interval and -max-interval must be positive")
+		return errUsage
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	n, err := env.client.WatchNotification(
+		ctx,
+		flags.Arg(0),
+		func(n notify.Notification) {
+			if env.json {
+				env.printJSON(n)
+				return
+			}
+			fmt.Fprintf(env.out, "%s\t%s\n", time.Now().Format(time.RFC3339), n.Status)
+		},
+		notify.PollInterval(*interval, *maxInterval),
+	)
+	if err != nil {
+		return err
+	}
+
+	if code, ok := statusExitCodes[n.Status]; ok {
+		if code != 0 {
+			return code
+		}
+		return nil
+	}
+	if n.Status.IsFailure() {
+		return failureExitCode
+	}
+	return nil
+}
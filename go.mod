@@ -1,6 +1,6 @@
 module github.com/govau/notify-client-go
 
-go 1.12
+go 1.13
 
 require (
 	golang.org/x/crypto v0.0.0-20190411191339-88737f569e3a // indirect
@@ -0,0 +1,48 @@
+package notify
+
+import "context"
+
+// EmailSender sends emails. It is implemented by *Client.
+type EmailSender interface {
+	SendEmail(id string, emailAddress string, options ...SendEmailOption) (SentEmail, error)
+	SendEmailContext(ctx context.Context, id string, emailAddress string, options ...SendEmailOption) (SentEmail, error)
+}
+
+// SMSSender sends text messages. It is implemented by *Client.
+type SMSSender interface {
+	SendSMS(id string, phoneNumber string, options ...SendSMSOption) (SentSMS, error)
+	SendSMSContext(ctx context.Context, id string, phoneNumber string, options ...SendSMSOption) (SentSMS, error)
+}
+
+// TemplateReader fetches and previews templates. It is implemented by *Client.
+type TemplateReader interface {
+	GetTemplateByID(id string) (Template, error)
+	GetTemplateByIDContext(ctx context.Context, id string) (Template, error)
+	GetTemplateByIDAndVersion(id string, version int) (Template, error)
+	GetTemplateByIDAndVersionContext(ctx context.Context, id string, version int) (Template, error)
+	GetAllTemplates(typ TemplateType) (Templates, error)
+	GetAllTemplatesContext(ctx context.Context, typ TemplateType) (Templates, error)
+	GenerateTemplatePreview(id string, personalisation ...PersonalisationOption) (TemplatePreview, error)
+	GenerateTemplatePreviewContext(ctx context.Context, id string, personalisation ...PersonalisationOption) (TemplatePreview, error)
+}
+
+// NotificationReader fetches notifications which have been sent. It is
+// implemented by *Client.
+type NotificationReader interface {
+	GetNotificationById(id string) (Notification, error)
+	GetNotificationByIdContext(ctx context.Context, id string) (Notification, error)
+	GetNotifications(filters ...NotificationsFilter) (NotificationsPage, error)
+	GetNotificationsContext(ctx context.Context, filters ...NotificationsFilter) (NotificationsPage, error)
+}
+
+// Notifier is the set of operations most services use, for depending on an
+// interface rather than *Client. The notifytest package provides a mock
+// implementation.
+type Notifier interface {
+	EmailSender
+	SMSSender
+	TemplateReader
+	NotificationReader
+}
+
+var _ Notifier = (*Client)(nil)
@@ -2,6 +2,7 @@ package base
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,7 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+	"unicode/utf8"
 
+	"github.com/govau/notify-client-go/internal/ratelimit"
 	"github.com/govau/notify-client-go/notifyapi"
 
 	jose "gopkg.in/square/go-jose.v2"
@@ -18,6 +21,10 @@ import (
 
 const NotifyBaseURL = "https://rest-api.notify.gov.au"
 
+// maxErrorBodySize is the most of an undecodable error response body kept in a
+// notifyapi.Error.
+const maxErrorBodySize = 512
+
 type Response struct {
 	response *http.Response
 	body     *bytes.Buffer
@@ -47,6 +54,15 @@ func (resp Response) JSON(v interface{}, at ...string) Response {
 	return resp
 }
 
+// Bytes returns the raw body of the response.
+func (resp Response) Bytes() ([]byte, error) {
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	return resp.body.Bytes(), nil
+}
+
 func (resp Response) JSONData(v interface{}) Response {
 	return resp.JSON(v, "data")
 }
@@ -62,6 +78,10 @@ type Client struct {
 	ServiceID   string
 	APIKey      string
 	RouteSecret string
+	RetryPolicy *RetryPolicy
+	// Limiter, if set, limits how often requests are sent. It is shared by
+	// copies of the client.
+	Limiter *ratelimit.Limiter
 }
 
 func createJWT(clientID, secret string) (string, error) {
@@ -80,16 +100,24 @@ func createJWT(clientID, secret string) (string, error) {
 }
 
 func (c Client) Do(req *http.Request) (*http.Response, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
 	token, err := createJWT(c.ServiceID, c.APIKey)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Content-type", "application/json")
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Add("X-Custom-Forwarder", "")
-	req.Header.Add("User-agent", "NOTIFY-API-GO-CLIENT/0.0.1")
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	if c.RouteSecret != "" {
+		req.Header.Set("X-Custom-Forwarder", c.RouteSecret)
+	}
+	req.Header.Set("User-agent", "NOTIFY-API-GO-CLIENT/0.0.1")
 
 	req.URL.Host = ""
 	req.URL.Scheme = ""
@@ -106,10 +134,42 @@ func (c Client) makeRequest(request *http.Request, options ...requestOption) Res
 		}
 	}
 
+	attempts := 1
+	if c.RetryPolicy != nil && !retryDisabled(request.Context()) {
+		attempts = c.RetryPolicy.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp := c.doRequest(request)
+		if resp.Error == nil || attempt >= attempts || !retryable(request, resp) {
+			return resp
+		}
+
+		wait := c.RetryPolicy.backoff(attempt)
+		if after, ok := retryAfter(resp); ok && after > wait {
+			wait = after
+		}
+
+		if err := sleep(request.Context(), wait); err != nil {
+			return BadResponse(err)
+		}
+
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return BadResponse(err)
+			}
+			request.Body = body
+		}
+	}
+}
+
+func (c Client) doRequest(request *http.Request) Response {
 	response, err := c.Do(request)
 	if err != nil {
 		return BadResponse(err)
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode >= 400 {
 		var body []byte
@@ -120,9 +180,10 @@ func (c Client) makeRequest(request *http.Request, options ...requestOption) Res
 		}
 
 		apiErr := &notifyapi.Error{}
-		if err := json.Unmarshal(body, apiErr); err != nil {
-			return BadResponse(err)
+		if err := json.Unmarshal(body, apiErr); err != nil || len(apiErr.Errors) == 0 {
+			apiErr = &notifyapi.Error{Body: truncate(body, maxErrorBodySize)}
 		}
+		apiErr.Code = response.StatusCode
 		apiErr.Header = response.Header
 
 		return BadResponse(apiErr)
@@ -144,20 +205,41 @@ func (c Client) makeRequest(request *http.Request, options ...requestOption) Res
 	}
 }
 
+// truncate returns body as a string of at most n bytes, without splitting a
+// UTF-8 sequence.
+func truncate(body []byte, n int) string {
+	if len(body) <= n {
+		return string(body)
+	}
+
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n--
+	}
+	return string(body[:n]) + "…"
+}
+
 func (c Client) Get(path string, options ...requestOption) Response {
+	return c.GetContext(context.Background(), path, options...)
+}
+
+func (c Client) GetContext(ctx context.Context, path string, options ...requestOption) Response {
 	req, err := http.NewRequest("GET", path, nil)
 	if err != nil {
 		return BadResponse(err)
 	}
 
-	return c.makeRequest(req, options...)
+	return c.makeRequest(req.WithContext(ctx), options...)
 }
 
 func (c Client) Post(path string, body io.Reader, options ...requestOption) Response {
+	return c.PostContext(context.Background(), path, body, options...)
+}
+
+func (c Client) PostContext(ctx context.Context, path string, body io.Reader, options ...requestOption) Response {
 	req, err := http.NewRequest("POST", path, body)
 	if err != nil {
 		return BadResponse(err)
 	}
 
-	return c.makeRequest(req, options...)
+	return c.makeRequest(req.WithContext(ctx), options...)
 }
@@ -0,0 +1,106 @@
+package base
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/govau/notify-client-go/notifyapi"
+)
+
+const (
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy controls how requests that fail with a transient error are
+// retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// backoff returns how long to wait before the given retry, using exponential
+// backoff with jitter.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	min, max := p.MinBackoff, p.MaxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	d := min
+	for i := 1; i < retry && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+type noRetryKey struct{}
+
+// WithoutRetry returns a context which disables retries for any request made
+// with it.
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func retryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetryKey{}).(bool)
+	return disabled
+}
+
+// retryable reports whether it is safe to send request again after it failed
+// with resp. Requests which change state are only retried when the server
+// tells us it did not process them.
+func retryable(request *http.Request, resp Response) bool {
+	if request.Context().Err() != nil {
+		return false
+	}
+
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return false
+	}
+
+	idempotent := request.Method == "GET" || request.Method == "HEAD"
+
+	apiErr, ok := resp.Error.(*notifyapi.Error)
+	if !ok {
+		return idempotent
+	}
+
+	if idempotent {
+		return apiErr.IsRetryable()
+	}
+	return apiErr.Code == http.StatusTooManyRequests || apiErr.Code == http.StatusServiceUnavailable
+}
+
+// retryAfter returns the delay requested by the Retry-After header in resp, if
+// any.
+func retryAfter(resp Response) (time.Duration, bool) {
+	apiErr, ok := resp.Error.(*notifyapi.Error)
+	if !ok {
+		return 0, false
+	}
+	return apiErr.RetryAfter()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,200 @@
+// Package placeholder parses and renders the placeholders in Notify templates.
+package placeholder
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\(\(([^()]+)\)\)`)
+	blankLinesPattern  = regexp.MustCompile(`\n{3,}`)
+)
+
+// Placeholder is a placeholder in a template, such as ((name)) or the
+// conditional ((has_card??Bring your card)).
+type Placeholder struct {
+	Name string
+	// Conditional is true if the placeholder shows Text depending on whether
+	// the value for Name is truthy.
+	Conditional bool
+	Text        string
+}
+
+func parse(match string) Placeholder {
+	inner := match[2 : len(match)-2]
+
+	if i := strings.Index(inner, "??"); i >= 0 {
+		return Placeholder{
+			Name:        strings.TrimSpace(inner[:i]),
+			Conditional: true,
+			Text:        inner[i+2:],
+		}
+	}
+	return Placeholder{Name: strings.TrimSpace(inner)}
+}
+
+// Parse returns every placeholder in body, in the order they appear.
+func Parse(body string) []Placeholder {
+	var placeholders []Placeholder
+	for _, match := range placeholderPattern.FindAllString(body, -1) {
+		placeholders = append(placeholders, parse(match))
+	}
+	return placeholders
+}
+
+// Key returns the form of a placeholder or personalisation name used to match
+// them. Notify ignores case, spaces, underscores and hyphens, so ((First name))
+// is filled in by first_name.
+func Key(name string) string {
+	return strings.ToLower(keyReplacer.Replace(name))
+}
+
+var keyReplacer = strings.NewReplacer(" ", "", "_", "", "-", "")
+
+// Names returns the unique names of the placeholders in bodies, in the order
+// they first appear. Names with the same Key are only returned once, and are
+// returned in lower case.
+func Names(bodies ...string) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	for _, body := range bodies {
+		for _, p := range Parse(body) {
+			if !seen[Key(p.Name)] {
+				seen[Key(p.Name)] = true
+				names = append(names, strings.ToLower(p.Name))
+			}
+		}
+	}
+	return names
+}
+
+// Format controls how list values are rendered.
+type Format int
+
+const (
+	// Inline renders lists separated by commas and a final "and", as in text
+	// messages and email subjects.
+	Inline Format = iota
+	// Bullets renders lists as bullet points on their own lines, as in email
+	// bodies.
+	Bullets
+)
+
+// Render substitutes values into body the way Notify does, returning the
+// names of any placeholders without a value. Placeholders without a value are
+// left in the output.
+func Render(body string, values map[string]interface{}, format Format) (string, []string) {
+	var missing []string
+
+	lookup := map[string]interface{}{}
+	for k, v := range values {
+		lookup[Key(k)] = v
+	}
+
+	body = strings.Replace(body, "\r\n", "\n", -1)
+	body = placeholderPattern.ReplaceAllStringFunc(body, func(match string) string {
+		p := parse(match)
+
+		value, ok := lookup[Key(p.Name)]
+		if !ok {
+			missing = append(missing, p.Name)
+			return match
+		}
+
+		if p.Conditional {
+			if truthy(value) {
+				return p.Text
+			}
+			return ""
+		}
+
+		if items, ok := list(value); ok {
+			if format == Inline {
+				return join(items)
+			}
+			return "\n\n• " + strings.Join(items, "\n• ") + "\n\n"
+		}
+
+		return fmt.Sprint(value)
+	})
+
+	if format == Bullets {
+		body = blankLinesPattern.ReplaceAllString(body, "\n\n")
+		if strings.HasSuffix(body, "\n\n") {
+			body = strings.TrimSuffix(body, "\n")
+		}
+	} else {
+		body = strings.TrimSpace(body)
+	}
+
+	return body, missing
+}
+
+// RenderTemplate renders the subject and body of a template of the given
+// type, returning the names of any placeholders without a value.
+func RenderTemplate(typ, subject, body string, values map[string]interface{}) (string, string, []string) {
+	format := Inline
+	if typ == "email" || typ == "letter" {
+		format = Bullets
+	}
+
+	renderedSubject, missingSubject := Render(subject, values, Inline)
+	renderedBody, missingBody := Render(body, values, format)
+
+	var missing []string
+	seen := map[string]bool{}
+	for _, name := range append(missingSubject, missingBody...) {
+		if !seen[Key(name)] {
+			seen[Key(name)] = true
+			missing = append(missing, name)
+		}
+	}
+
+	return renderedSubject, renderedBody, missing
+}
+
+// list returns the items of value as strings if it is a slice.
+func list(value interface{}) ([]string, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	var items []string
+	for i := 0; i < v.Len(); i++ {
+		item := fmt.Sprint(v.Index(i).Interface())
+		if strings.TrimSpace(item) != "" {
+			items = append(items, item)
+		}
+	}
+	return items, true
+}
+
+// join formats items the way Notify does inline, as in "a, b and c".
+func join(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes", "y", "true", "t", "1":
+			return true
+		}
+	case int:
+		return v == 1
+	case float64:
+		return v == 1
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// PostageClass is the class of postage used to send a letter.
+type PostageClass string
+
+const (
+	FirstClass  PostageClass = "first"
+	SecondClass PostageClass = "second"
+)
+
+// Address is the lines of a postal address, with the postcode on the last
+// line. An address must have between 3 and 7 lines.
+type Address []string
+
+func (address Address) validate() error {
+	if len(address) < 3 {
+		return errors.New("address must have at least 3 lines")
+	}
+	if len(address) > 7 {
+		return errors.New("address must have at most 7 lines")
+	}
+	return nil
+}
+
+// updateLetterPayload adds the address lines to the letter's personalisation.
+func (address Address) updateLetterPayload(p payload) payload {
+	dict := map[string]interface{}{}
+	for k, v := range p.personalisation() {
+		dict[k] = v
+	}
+
+	for i, line := range address {
+		dict["address_line_"+strconv.Itoa(i+1)] = line
+	}
+
+	return append(p, payloadItem{"personalisation", dict})
+}
+
+type SentLetter struct {
+	ID           string     `json:"id"`
+	URI          string     `json:"uri"`
+	Reference    *string    `json:"reference"`
+	ScheduledFor *time.Time `json:"scheduled_for"`
+
+	Content struct {
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+	} `json:"content"`
+
+	Template struct {
+		ID      string `json:"id"`
+		URI     string `json:"uri"`
+		Version int    `json:"version"`
+	} `json:"template"`
+}
+
+type SentPrecompiledLetter struct {
+	ID        string       `json:"id"`
+	Reference string       `json:"reference"`
+	Postage   PostageClass `json:"postage"`
+}
+
+// Address returns the lines of a letter's address which are not empty,
+// followed by the postcode if it was given separately.
+func (n Notification) Address() Address {
+	var address Address
+	for _, line := range []string{n.Line1, n.Line2, n.Line3, n.Line4, n.Line5, n.Line6, n.Line7, n.Postcode} {
+		if line != "" {
+			address = append(address, line)
+		}
+	}
+	return address
+}
+
+func (c Client) SendLetter(
+	id string,
+	address Address,
+	options ...SendLetterOption,
+) (SentLetter, error) {
+	return c.SendLetterContext(context.Background(), id, address, options...)
+}
+
+func (c Client) SendLetterContext(
+	ctx context.Context,
+	id string,
+	address Address,
+	options ...SendLetterOption,
+) (SentLetter, error) {
+	var response SentLetter
+	var buf bytes.Buffer
+	var p = payload{
+		{"template_id", id},
+	}
+
+	if err := address.validate(); err != nil {
+		return response, fmt.Errorf("notify: %v", err)
+	}
+
+	for _, option := range options {
+		p = option.updateLetterPayload(p)
+	}
+	p = address.updateLetterPayload(p)
+
+	if err := p.validate(); err != nil {
+		return response, err
+	}
+
+	err := json.NewEncoder(&buf).Encode(p)
+	if err != nil {
+		return response, err
+	}
+
+	err = c.c.PostContext(ctx, "./v2/notifications/letter", &buf).JSON(&response).Error
+	return response, err
+}
+
+// SendPrecompiledLetter sends a letter which has already been rendered as a
+// PDF. The reference is required to identify the letter.
+func (c Client) SendPrecompiledLetter(
+	reference string,
+	pdf io.Reader,
+	options ...SendPrecompiledLetterOption,
+) (SentPrecompiledLetter, error) {
+	return c.SendPrecompiledLetterContext(context.Background(), reference, pdf, options...)
+}
+
+func (c Client) SendPrecompiledLetterContext(
+	ctx context.Context,
+	reference string,
+	pdf io.Reader,
+	options ...SendPrecompiledLetterOption,
+) (SentPrecompiledLetter, error) {
+	var response SentPrecompiledLetter
+	var content bytes.Buffer
+
+	if reference == "" {
+		return response, errors.New("notify: reference is required for precompiled letters")
+	}
+
+	encoder := base64.NewEncoder(base64.StdEncoding, &content)
+	if _, err := io.Copy(encoder, pdf); err != nil {
+		return response, err
+	}
+	if err := encoder.Close(); err != nil {
+		return response, err
+	}
+
+	var p = payload{
+		{"reference", reference},
+		{"content", content.String()},
+	}
+
+	for _, option := range options {
+		p = option.updatePrecompiledLetterPayload(p)
+	}
+
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(p)
+	if err != nil {
+		return response, err
+	}
+
+	err = c.c.PostContext(ctx, "./v2/notifications/letter", &buf).JSON(&response).Error
+	return response, err
+}
+
+// GetLetterPDF downloads the rendered PDF of a letter notification.
+func (c Client) GetLetterPDF(id string) ([]byte, error) {
+	return c.GetLetterPDFContext(context.Background(), id)
+}
+
+func (c Client) GetLetterPDFContext(ctx context.Context, id string) ([]byte, error) {
+	return c.c.GetContext(ctx, "./v2/notifications/"+id+"/pdf").Bytes()
+}
@@ -0,0 +1,178 @@
+package notify
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/govau/notify-client-go/internal/base"
+)
+
+// Links contains the pagination links returned with a page of results.
+type Links struct {
+	Current string `json:"current"`
+	Next    string `json:"next"`
+}
+
+// olderThan returns the older_than cursor for the page after this one, or an
+// empty string if there are no more pages.
+func (l Links) olderThan() string {
+	if l.Next == "" {
+		return ""
+	}
+
+	next, err := url.Parse(l.Next)
+	if err != nil {
+		return ""
+	}
+
+	return next.Query().Get("older_than")
+}
+
+// NotificationsPage is a single page of notifications, newest first.
+type NotificationsPage struct {
+	Notifications []Notification `json:"notifications"`
+	Links         Links          `json:"links"`
+}
+
+// NotificationsFilter narrows down the notifications returned by
+// GetNotifications.
+type NotificationsFilter interface {
+	updateQuery(base.QueryValues) base.QueryValues
+}
+
+type updateQueryFunc func(base.QueryValues) base.QueryValues
+
+func (fn updateQueryFunc) updateQuery(q base.QueryValues) base.QueryValues {
+	return fn(q)
+}
+
+func queryValue(key, value string) struct{ Key, Value string } {
+	return struct{ Key, Value string }{key, value}
+}
+
+// FilterByTemplateType only returns notifications of the given type, such as
+// email or sms.
+func FilterByTemplateType(typ TemplateType) NotificationsFilter {
+	return updateQueryFunc(func(q base.QueryValues) base.QueryValues {
+		return append(q, queryValue("template_type", string(typ)))
+	})
+}
+
+// FilterByStatus only returns notifications with one of the given statuses.
+func FilterByStatus(statuses ...NotificationStatus) NotificationsFilter {
+	return updateQueryFunc(func(q base.QueryValues) base.QueryValues {
+		for _, status := range statuses {
+			q = append(q, queryValue("status", string(status)))
+		}
+		return q
+	})
+}
+
+// FilterByReference only returns notifications sent with the given reference.
+func FilterByReference(reference string) NotificationsFilter {
+	return updateQueryFunc(func(q base.QueryValues) base.QueryValues {
+		return append(q, queryValue("reference", reference))
+	})
+}
+
+// OlderThan only returns notifications sent before the notification with the
+// given ID. It is used to fetch the next page of results.
+func OlderThan(id string) NotificationsFilter {
+	return updateQueryFunc(func(q base.QueryValues) base.QueryValues {
+		var filtered base.QueryValues
+		for _, item := range q {
+			if item.Key != "older_than" {
+				filtered = append(filtered, item)
+			}
+		}
+		return append(filtered, queryValue("older_than", id))
+	})
+}
+
+func (c Client) GetNotifications(filters ...NotificationsFilter) (NotificationsPage, error) {
+	return c.GetNotificationsContext(context.Background(), filters...)
+}
+
+func (c Client) GetNotificationsContext(ctx context.Context, filters ...NotificationsFilter) (NotificationsPage, error) {
+	var query base.QueryValues
+	for _, filter := range filters {
+		query = filter.updateQuery(query)
+	}
+
+	var page NotificationsPage
+	err := c.c.GetContext(ctx, "./v2/notifications", query).JSON(&page).Error
+	return page, err
+}
+
+// NotificationIterator walks through every page of notifications matching a
+// set of filters.
+//
+//	it := client.Notifications(ctx, notify.FilterByStatus(notify.StatusDelivered))
+//	for it.Next() {
+//		n := it.Notification()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type NotificationIterator struct {
+	client  Client
+	ctx     context.Context
+	filters []NotificationsFilter
+
+	page      []Notification
+	current   Notification
+	olderThan string
+	last      bool
+	err       error
+}
+
+// Notifications returns an iterator over all notifications matching filters,
+// fetching further pages as they are needed.
+func (c Client) Notifications(ctx context.Context, filters ...NotificationsFilter) *NotificationIterator {
+	return &NotificationIterator{client: c, ctx: ctx, filters: filters}
+}
+
+// Next advances the iterator to the next notification. It returns false when
+// there are no more notifications or an error occurred.
+func (it *NotificationIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.last || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *NotificationIterator) fetch() {
+	filters := it.filters
+	if it.olderThan != "" {
+		filters = append(filters[:len(filters):len(filters)], OlderThan(it.olderThan))
+	}
+
+	page, err := it.client.GetNotificationsContext(it.ctx, filters...)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page = page.Notifications
+	it.olderThan = page.Links.olderThan()
+	if it.olderThan == "" && page.Links.Next != "" && len(page.Notifications) > 0 {
+		it.olderThan = page.Notifications[len(page.Notifications)-1].ID
+	}
+	it.last = it.olderThan == "" || len(page.Notifications) == 0
+}
+
+// Notification returns the notification the iterator is currently at.
+func (it *NotificationIterator) Notification() Notification {
+	return it.current
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *NotificationIterator) Err() error {
+	return it.err
+}
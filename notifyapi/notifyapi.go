@@ -1,8 +1,40 @@
 package notifyapi
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+)
+
+// Sentinel errors for classifying an Error with errors.Is.
+var (
+	// ErrBadRequest matches errors caused by an invalid request, such as a
+	// validation error or missing personalisation.
+	ErrBadRequest = errors.New("notify: bad request")
+	// ErrAuth matches errors caused by an invalid or expired API key.
+	ErrAuth = errors.New("notify: authentication failed")
+	// ErrNotFound matches errors for a notification or template which does not
+	// exist.
+	ErrNotFound = errors.New("notify: not found")
+	// ErrRateLimited matches errors caused by exceeding the rate limit or daily
+	// message limit.
+	ErrRateLimited = errors.New("notify: rate limited")
+	// ErrServer matches errors caused by a failure in Notify.
+	ErrServer = errors.New("notify: server error")
+)
+
+// Names of the errors Notify returns in ErrorItem.Error.
+const (
+	BadRequestError      = "BadRequestError"
+	ValidationError      = "ValidationError"
+	AuthError            = "AuthError"
+	NoResultFound        = "NoResultFound"
+	TooManyRequestsError = "TooManyRequestsError"
+	RateLimitError       = "RateLimitError"
+	ServerError          = "Exception"
 )
 
 // Error contains an error response from the server.
@@ -13,6 +45,10 @@ type Error struct {
 	Header http.Header
 
 	Errors []ErrorItem `json:"errors"`
+
+	// Body contains the start of the response body when it could not be
+	// decoded, such as an HTML error page from a proxy.
+	Body string `json:"-"`
 }
 
 type ErrorItem struct {
@@ -21,9 +57,81 @@ type ErrorItem struct {
 }
 
 func (e Error) Error() string {
+	if len(e.Errors) == 0 {
+		if e.Body != "" {
+			return fmt.Sprintf("notify: %d %s: %s", e.Code, http.StatusText(e.Code), e.Body)
+		}
+		return fmt.Sprintf("notify: %d %s", e.Code, http.StatusText(e.Code))
+	}
+
 	var allErrors []string
 	for _, v := range e.Errors {
 		allErrors = append(allErrors, v.Message)
 	}
 	return strings.Join(allErrors, ", ")
 }
+
+// Is reports whether e is classified as target, one of the sentinel errors in
+// this package.
+func (e Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.Code == http.StatusBadRequest || e.Has(BadRequestError) || e.Has(ValidationError)
+	case ErrAuth:
+		return e.Code == http.StatusUnauthorized || e.Code == http.StatusForbidden || e.Has(AuthError)
+	case ErrNotFound:
+		return e.Code == http.StatusNotFound || e.Has(NoResultFound)
+	case ErrRateLimited:
+		return e.Code == http.StatusTooManyRequests || e.Has(TooManyRequestsError) || e.Has(RateLimitError)
+	case ErrServer:
+		return e.Code >= 500
+	}
+	return false
+}
+
+// Has reports whether the response contains an error with the given name,
+// such as ValidationError.
+func (e Error) Has(name string) bool {
+	for _, item := range e.Errors {
+		if item.Error == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable reports whether the same request may succeed if it is sent
+// again later.
+func (e Error) IsRetryable() bool {
+	switch e.Code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RetryAfter returns how long the server asked the client to wait before
+// retrying, from the Retry-After header. A date in the past returns 0.
+func (e Error) RetryAfter() (time.Duration, bool) {
+	value := e.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if when, err := http.ParseTime(value); err == nil {
+		if after := time.Until(when); after > 0 {
+			return after, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
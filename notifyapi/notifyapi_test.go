@@ -0,0 +1,102 @@
+package notifyapi_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/govau/notify-client-go/notifyapi"
+)
+
+func TestErrorIs(t *testing.T) {
+	sentinels := []error{
+		notifyapi.ErrBadRequest,
+		notifyapi.ErrAuth,
+		notifyapi.ErrNotFound,
+		notifyapi.ErrRateLimited,
+		notifyapi.ErrServer,
+	}
+
+	tests := []struct {
+		name string
+		err  *notifyapi.Error
+		want error
+	}{
+		{
+			name: "validation error",
+			err:  &notifyapi.Error{Code: 400, Errors: []notifyapi.ErrorItem{{notifyapi.ValidationError, "phone_number Not a valid number"}}},
+			want: notifyapi.ErrBadRequest,
+		},
+		{
+			name: "invalid token",
+			err:  &notifyapi.Error{Code: 403, Errors: []notifyapi.ErrorItem{{notifyapi.AuthError, "Invalid token: signature"}}},
+			want: notifyapi.ErrAuth,
+		},
+		{
+			name: "no result",
+			err:  &notifyapi.Error{Code: 404, Errors: []notifyapi.ErrorItem{{notifyapi.NoResultFound, "No result found"}}},
+			want: notifyapi.ErrNotFound,
+		},
+		{
+			name: "rate limited",
+			err:  &notifyapi.Error{Code: 429, Errors: []notifyapi.ErrorItem{{notifyapi.TooManyRequestsError, "Exceeded send limits"}}},
+			want: notifyapi.ErrRateLimited,
+		},
+		{
+			name: "bad gateway",
+			err:  &notifyapi.Error{Code: 502},
+			want: notifyapi.ErrServer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("sending reminder: %w", tt.err)
+
+			for _, sentinel := range sentinels {
+				if got := errors.Is(wrapped, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v", sentinel, got)
+				}
+			}
+
+			var apiErr *notifyapi.Error
+			if !errors.As(wrapped, &apiErr) || apiErr.Code != tt.err.Code {
+				t.Errorf("errors.As did not find the notifyapi.Error")
+			}
+		})
+	}
+}
+
+func TestErrorRetry(t *testing.T) {
+	err := notifyapi.Error{
+		Code:   http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": []string{"3"}},
+	}
+	if !err.IsRetryable() {
+		t.Error("a 429 should be retryable")
+	}
+	if after, ok := err.RetryAfter(); !ok || after != 3*time.Second {
+		t.Errorf("got RetryAfter() = %v, %v, want 3s", after, ok)
+	}
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	err.Header.Set("Retry-After", date)
+	if after, ok := err.RetryAfter(); !ok || after <= 0 || after > time.Minute {
+		t.Errorf("got RetryAfter() = %v, %v, want up to a minute", after, ok)
+	}
+
+	date = time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	err.Header.Set("Retry-After", date)
+	if after, ok := err.RetryAfter(); !ok || after != 0 {
+		t.Errorf("got RetryAfter() = %v, %v, want 0 for a date in the past", after, ok)
+	}
+
+	err = notifyapi.Error{Code: http.StatusBadRequest}
+	if err.IsRetryable() {
+		t.Error("a 400 should not be retryable")
+	}
+	if _, ok := err.RetryAfter(); ok {
+		t.Error("RetryAfter() should be false without a header")
+	}
+}
@@ -0,0 +1,175 @@
+package notifytest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/govau/notify-client-go/notifyapi"
+)
+
+// Endpoint identifies an API endpoint served by the fake server.
+type Endpoint string
+
+const (
+	SendEmail          Endpoint = "POST /v2/notifications/email"
+	SendSMS            Endpoint = "POST /v2/notifications/sms"
+	GetNotifications   Endpoint = "GET /v2/notifications"
+	GetNotification    Endpoint = "GET /v2/notifications/{id}"
+	GetTemplates       Endpoint = "GET /v2/templates"
+	GetTemplate        Endpoint = "GET /v2/template/{id}"
+	GetTemplateVersion Endpoint = "GET /v2/template/{id}/version/{version}"
+	PreviewTemplate    Endpoint = "POST /v2/template/{id}/preview"
+
+	// AnyEndpoint matches every endpoint. Its calls are counted across all
+	// endpoints.
+	AnyEndpoint Endpoint = ""
+)
+
+// Fault changes how the server responds to a request. It returns true if it
+// wrote a response, or false to let the server respond normally.
+type Fault func(w http.ResponseWriter, r *http.Request, closed <-chan struct{}) bool
+
+// Scenario injects a fault into calls to an endpoint.
+type Scenario struct {
+	Endpoint Endpoint
+	Fault    Fault
+
+	// From is the call to the endpoint, counting from 1 since the server
+	// started, that the fault first applies to. If zero, the fault applies
+	// from the next call.
+	From int
+	// Times is the number of calls the fault applies to. If zero, the fault
+	// applies to every call from From onwards.
+	Times int
+}
+
+func (sc Scenario) applies(calls map[Endpoint]int, endpoint Endpoint) bool {
+	if sc.Endpoint != AnyEndpoint && sc.Endpoint != endpoint {
+		return false
+	}
+	call := calls[sc.Endpoint]
+	if call < sc.From {
+		return false
+	}
+	return sc.Times == 0 || call < sc.From+sc.Times
+}
+
+// Inject adds a scenario to the server. When several scenarios apply to the
+// same call, the first one injected is used.
+func (s *Server) Inject(scenarios ...Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sc := range scenarios {
+		if sc.From == 0 {
+			sc.From = s.calls[sc.Endpoint] + 1
+		}
+		s.scenarios = append(s.scenarios, sc)
+	}
+}
+
+// ClearFaults removes every scenario from the server.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenarios = nil
+}
+
+// Calls returns the number of requests the server has received for endpoint.
+func (s *Server) Calls(endpoint Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[endpoint]
+}
+
+// injectFault counts a call to endpoint and applies the first matching
+// scenario, returning true if it wrote a response.
+func (s *Server) injectFault(endpoint Endpoint, w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	s.calls[endpoint]++
+	s.calls[AnyEndpoint]++
+
+	var fault Fault
+	for _, sc := range s.scenarios {
+		if sc.applies(s.calls, endpoint) {
+			fault = sc.Fault
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if fault == nil {
+		return false
+	}
+	return fault(w, r, s.closed)
+}
+
+// RateLimited responds with a 429 and a Retry-After header. The header is in
+// whole seconds, so retryAfter is rounded up to the next second.
+func RateLimited(retryAfter time.Duration) Fault {
+	seconds := (retryAfter + time.Second - 1) / time.Second
+	if seconds < 0 {
+		seconds = 0
+	}
+	return func(w http.ResponseWriter, r *http.Request, closed <-chan struct{}) bool {
+		w.Header().Set("Retry-After", strconv.Itoa(int(seconds)))
+		writeError(w, http.StatusTooManyRequests, "TooManyRequestsError", "Exceeded rate limit for key type LIVE of 3000 requests per 60 seconds")
+		return true
+	}
+}
+
+// ServerError responds with status and a body which is not JSON, such as an
+// HTML error page from a proxy.
+func ServerError(status int, body string) Fault {
+	return func(w http.ResponseWriter, r *http.Request, closed <-chan struct{}) bool {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+		return true
+	}
+}
+
+// APIError responds with a Notify error response.
+func APIError(status int, errs ...notifyapi.ErrorItem) Fault {
+	return func(w http.ResponseWriter, r *http.Request, closed <-chan struct{}) bool {
+		writeJSON(w, status, errorResponse{status, errs})
+		return true
+	}
+}
+
+// ValidationError responds with a 400 validation error with message.
+func ValidationError(message string) Fault {
+	return APIError(http.StatusBadRequest, notifyapi.ErrorItem{Error: "ValidationError", Message: message})
+}
+
+// Timeout never responds, until the client gives up or the server is closed.
+func Timeout() Fault {
+	return func(w http.ResponseWriter, r *http.Request, closed <-chan struct{}) bool {
+		select {
+		case <-r.Context().Done():
+		case <-closed:
+		}
+		return true
+	}
+}
+
+// Slow delays the response by d, then responds normally.
+func Slow(d time.Duration) Fault {
+	return func(w http.ResponseWriter, r *http.Request, closed <-chan struct{}) bool {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-r.Context().Done():
+			return true
+		case <-closed:
+			return true
+		case <-timer.C:
+			return false
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package notifytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/internal/placeholder"
+	"github.com/govau/notify-client-go/notifyapi"
+
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// pageSize is the number of notifications returned per page.
+const pageSize = 250
+
+type errorResponse struct {
+	Code   int                   `json:"status_code"`
+	Errors []notifyapi.ErrorItem `json:"errors"`
+}
+
+type sendRequest struct {
+	TemplateID      string                 `json:"template_id"`
+	EmailAddress    string                 `json:"email_address"`
+	PhoneNumber     string                 `json:"phone_number"`
+	Personalisation map[string]interface{} `json:"personalisation"`
+	Reference       *string                `json:"reference"`
+	ScheduledFor    *string                `json:"scheduled_for"`
+}
+
+type route struct {
+	endpoint Endpoint
+	method   string
+	parts    []string
+	handle   func(s *Server, w http.ResponseWriter, r *http.Request, path []string)
+}
+
+var routes = []route{
+	{SendEmail, "POST", []string{"v2", "notifications", "email"}, func(s *Server, w http.ResponseWriter, r *http.Request, path []string) {
+		s.sendEmail(w, r)
+	}},
+	{SendSMS, "POST", []string{"v2", "notifications", "sms"}, func(s *Server, w http.ResponseWriter, r *http.Request, path []string) {
+		s.sendSMS(w, r)
+	}},
+	{GetNotifications, "GET", []string{"v2", "notifications"}, func(s *Server, w http.ResponseWriter, r *http.Request, path []string) {
+		s.getNotifications(w, r)
+	}},
+	{GetNotification, "GET", []string{"v2", "notifications", "*"}, func(s *Server, w http.ResponseWriter, r *http.Request, path []string) {
+		s.getNotification(w, path[2])
+	}},
+	{GetTemplates, "GET", []string{"v2", "templates"}, func(s *Server, w http.ResponseWriter, r *http.Request, path []string) {
+		s.getTemplates(w, r)
+	}},
+	{GetTemplate, "GET", []string{"v2", "template", "*"}, func(s *Server, w http.ResponseWriter, r *http.Request, path []string) {
+		s.getTemplate(w, path[2], "")
+	}},
+	{GetTemplateVersion, "GET", []string{"v2", "template", "*", "version", "*"}, func(s *Server, w http.ResponseWriter, r *http.Request, path []string) {
+		s.getTemplate(w, path[2], path[4])
+	}},
+	{PreviewTemplate, "POST", []string{"v2", "template", "*", "preview"}, func(s *Server, w http.ResponseWriter, r *http.Request, path []string) {
+		s.previewTemplate(w, r, path[2])
+	}},
+}
+
+func (rt route) match(method string, path []string) bool {
+	if method != rt.method || len(path) != len(rt.parts) {
+		return false
+	}
+	for i, part := range rt.parts {
+		if part != "*" && part != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	for _, rt := range routes {
+		if !rt.match(r.Method, path) {
+			continue
+		}
+
+		if s.injectFault(rt.endpoint, w, r) {
+			return
+		}
+
+		if err := s.authenticate(r); err != nil {
+			writeError(w, http.StatusForbidden, "AuthError", "Invalid token: "+err.Error())
+			return
+		}
+
+		rt.handle(s, w, r, path)
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "NoResultFound", "No result found")
+}
+
+// authenticate checks the request is signed with the server's API key, the
+// same way Notify does.
+func (s *Server) authenticate(r *http.Request) error {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return fmt.Errorf("authorization header is missing or not a bearer token")
+	}
+
+	token, err := jwt.ParseSigned(strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		return err
+	}
+
+	var claims jwt.Claims
+	if err := token.Claims([]byte(s.secret), &claims); err != nil {
+		return fmt.Errorf("signature, api token not found")
+	}
+
+	now := time.Now()
+	if err := claims.ValidateWithLeeway(jwt.Expected{Issuer: s.serviceID, Time: now}, 30*time.Second); err != nil {
+		return err
+	}
+	if claims.IssuedAt == nil || now.Sub(claims.IssuedAt.Time()) > 30*time.Second {
+		return fmt.Errorf("expired token")
+	}
+
+	return nil
+}
+
+func (s *Server) sendEmail(w http.ResponseWriter, r *http.Request) {
+	var req sendRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.EmailAddress == "" {
+		writeError(w, http.StatusBadRequest, "ValidationError", "email_address is a required property")
+		return
+	}
+
+	m, ok := s.send(w, req, "email", req.EmailAddress)
+	if !ok {
+		return
+	}
+
+	var response notify.SentEmail
+	response.ID = m.ID
+	response.URI = s.URL + "/v2/notifications/" + m.ID
+	response.Reference = req.Reference
+	response.ScheduledFor = m.ScheduledFor
+	response.Content.Subject = m.Subject
+	response.Content.Body = m.Body
+	response.Content.FromEmail = "notify@example.gov.au"
+	response.Template.ID = m.Template.ID
+	response.Template.URI = m.Template.URI
+	response.Template.Version = m.Template.Version
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func (s *Server) sendSMS(w http.ResponseWriter, r *http.Request) {
+	var req sendRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.PhoneNumber == "" {
+		writeError(w, http.StatusBadRequest, "ValidationError", "phone_number is a required property")
+		return
+	}
+
+	m, ok := s.send(w, req, "sms", req.PhoneNumber)
+	if !ok {
+		return
+	}
+
+	var response notify.SentSMS
+	response.ID = m.ID
+	response.URI = s.URL + "/v2/notifications/" + m.ID
+	response.Reference = req.Reference
+	response.ScheduledFor = m.ScheduledFor
+	response.Content.Body = m.Body
+	response.Content.FromNumber = "Notify"
+	response.Template.ID = m.Template.ID
+	response.Template.URI = m.Template.URI
+	response.Template.Version = m.Template.Version
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// send renders and records a notification, writing an error response if it
+// cannot be sent.
+func (s *Server) send(w http.ResponseWriter, req sendRequest, typ notify.TemplateType, recipient string) (Message, bool) {
+	template, ok := s.template(req.TemplateID, 0)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "BadRequestError", "Template not found")
+		return Message{}, false
+	}
+	if template.Type != typ {
+		writeError(w, http.StatusBadRequest, "BadRequestError", fmt.Sprintf("%s template is not suitable for %s notification", template.Type, typ))
+		return Message{}, false
+	}
+
+	var scheduledFor *time.Time
+	if req.ScheduledFor != nil {
+		location, err := time.LoadLocation(notify.ScheduleTimeZone)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Exception", err.Error())
+			return Message{}, false
+		}
+		t, err := time.ParseInLocation(notify.ScheduledForLayout, *req.ScheduledFor, location)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "ValidationError", "scheduled_for datetime format is invalid. It must be a valid ISO8601 date time format, https://en.wikipedia.org/wiki/ISO_8601")
+			return Message{}, false
+		}
+		scheduledFor = &t
+
+		if scheduledFor.Before(time.Now()) {
+			writeError(w, http.StatusBadRequest, "ValidationError", "scheduled_for datetime can not be in the past")
+			return Message{}, false
+		}
+		if scheduledFor.After(time.Now().Add(notify.MaxSchedule)) {
+			writeError(w, http.StatusBadRequest, "ValidationError", "scheduled_for datetime can only be 24 hours in the future")
+			return Message{}, false
+		}
+	}
+
+	preview, ok := renderTemplate(w, template, req.Personalisation)
+	if !ok {
+		return Message{}, false
+	}
+
+	var m Message
+	m.ID = newID()
+	m.Type = typ
+	m.Status = notify.StatusCreated
+	m.Subject = preview.Subject
+	m.Body = preview.Body
+	m.CreatedAt = now()
+	m.ScheduledFor = scheduledFor
+	m.Template.ID = template.ID
+	m.Template.URI = s.URL + "/v2/template/" + template.ID + "/version/" + strconv.Itoa(template.Version)
+	m.Template.Version = template.Version
+	m.Personalisation = req.Personalisation
+	if req.Reference != nil {
+		m.Reference = *req.Reference
+	}
+	if typ == notify.TemplateTypeEmail {
+		m.EmailAddress = recipient
+	} else {
+		m.PhoneNumber = recipient
+	}
+
+	s.mu.Lock()
+	s.messages = append(s.messages, m)
+	s.mu.Unlock()
+
+	return m, true
+}
+
+func (s *Server) getNotification(w http.ResponseWriter, id string) {
+	for _, m := range s.Messages() {
+		if m.ID == id {
+			writeJSON(w, http.StatusOK, m.Notification)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "NoResultFound", "No result found")
+}
+
+func (s *Server) getNotifications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	olderThan := query.Get("older_than")
+	statuses := map[notify.NotificationStatus]bool{}
+	for _, status := range query["status"] {
+		statuses[notify.NotificationStatus(status)] = true
+	}
+
+	messages := s.Messages()
+	notifications := []notify.Notification{}
+	more := false
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		m := messages[i]
+		if olderThan != "" {
+			if m.ID == olderThan {
+				olderThan = ""
+			}
+			continue
+		}
+		if typ := query.Get("template_type"); typ != "" && m.Type != notify.TemplateType(typ) {
+			continue
+		}
+		if ref := query.Get("reference"); ref != "" && m.Reference != ref {
+			continue
+		}
+		if len(statuses) > 0 && !statuses[m.Status] && !(statuses[notify.StatusFailed] && m.Status.IsFailure()) {
+			continue
+		}
+		if len(notifications) == pageSize {
+			more = true
+			break
+		}
+		notifications = append(notifications, m.Notification)
+	}
+
+	var page notify.NotificationsPage
+	page.Notifications = notifications
+	page.Links.Current = s.URL + r.URL.String()
+	if more {
+		next := url.Values{}
+		for key, values := range query {
+			next[key] = values
+		}
+		next.Set("older_than", notifications[len(notifications)-1].ID)
+		page.Links.Next = s.URL + "/v2/notifications?" + next.Encode()
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) getTemplates(w http.ResponseWriter, r *http.Request) {
+	typ := r.URL.Query().Get("type")
+
+	s.mu.Lock()
+	templates := notify.Templates{}
+	for _, id := range s.order {
+		versions := s.templates[id]
+		latest := versions[len(versions)-1]
+		if typ == "" || latest.Type == notify.TemplateType(typ) {
+			templates = append(templates, latest)
+		}
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"templates": templates})
+}
+
+// getTemplate writes the template with the given version, or the latest
+// version if version is empty.
+func (s *Server) getTemplate(w http.ResponseWriter, id, version string) {
+	var v int
+	if version != "" {
+		var err error
+		v, err = strconv.Atoi(version)
+		if err != nil || v < 1 {
+			writeError(w, http.StatusBadRequest, "ValidationError", "version is not a valid integer")
+			return
+		}
+	}
+
+	template, ok := s.template(id, v)
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoResultFound", "No result found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, template)
+}
+
+func (s *Server) previewTemplate(w http.ResponseWriter, r *http.Request, id string) {
+	var req sendRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	template, ok := s.template(id, 0)
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoResultFound", "No result found")
+		return
+	}
+
+	preview, ok := renderTemplate(w, template, req.Personalisation)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preview)
+}
+
+// renderTemplate renders template with personalisation, writing an error
+// response if any personalisation is missing.
+func renderTemplate(w http.ResponseWriter, template notify.Template, personalisation map[string]interface{}) (notify.TemplatePreview, bool) {
+	subject, body, missing := placeholder.RenderTemplate(string(template.Type), template.Subject, template.Body, personalisation)
+	if len(missing) > 0 {
+		writeError(w, http.StatusBadRequest, "BadRequestError", "Missing personalisation: "+strings.Join(missing, ", "))
+		return notify.TemplatePreview{}, false
+	}
+
+	return notify.TemplatePreview{
+		ID:      template.ID,
+		Type:    template.Type,
+		Version: template.Version,
+		Subject: subject,
+		Body:    body,
+	}, true
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequestError", "Invalid JSON supplied in POST data")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, name, message string) {
+	writeJSON(w, status, errorResponse{status, []notifyapi.ErrorItem{{Error: name, Message: message}}})
+}
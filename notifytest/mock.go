@@ -0,0 +1,175 @@
+package notifytest
+
+import (
+	"context"
+	"sync"
+
+	notify "github.com/govau/notify-client-go"
+)
+
+// Call is a call made to a Mock.
+type Call struct {
+	// Method is the name of the method called, without the Context suffix.
+	Method string
+	// Args are the arguments of the call, excluding the context. Options are
+	// included as they were passed, so personalisation can be checked by
+	// asserting an option is a notify.Personalisation.
+	Args []interface{}
+}
+
+// Mock is an in-memory implementation of notify.Notifier which records every
+// call made to it. Each method calls the matching function field if it is set,
+// and otherwise returns a zero value, with a new ID for sent notifications.
+type Mock struct {
+	SendEmailFunc                 func(ctx context.Context, id, emailAddress string, options ...notify.SendEmailOption) (notify.SentEmail, error)
+	SendSMSFunc                   func(ctx context.Context, id, phoneNumber string, options ...notify.SendSMSOption) (notify.SentSMS, error)
+	GetTemplateByIDFunc           func(ctx context.Context, id string) (notify.Template, error)
+	GetTemplateByIDAndVersionFunc func(ctx context.Context, id string, version int) (notify.Template, error)
+	GetAllTemplatesFunc           func(ctx context.Context, typ notify.TemplateType) (notify.Templates, error)
+	GenerateTemplatePreviewFunc   func(ctx context.Context, id string, personalisation ...notify.PersonalisationOption) (notify.TemplatePreview, error)
+	GetNotificationByIdFunc       func(ctx context.Context, id string) (notify.Notification, error)
+	GetNotificationsFunc          func(ctx context.Context, filters ...notify.NotificationsFilter) (notify.NotificationsPage, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ notify.Notifier = (*Mock)(nil)
+
+// Calls returns the calls made to the mock, in order.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made to the named method, in order.
+func (m *Mock) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls made to the mock.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+}
+
+func (m *Mock) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func (m *Mock) SendEmail(id, emailAddress string, options ...notify.SendEmailOption) (notify.SentEmail, error) {
+	return m.SendEmailContext(context.Background(), id, emailAddress, options...)
+}
+
+func (m *Mock) SendEmailContext(ctx context.Context, id, emailAddress string, options ...notify.SendEmailOption) (notify.SentEmail, error) {
+	m.record("SendEmail", id, emailAddress, options)
+	if m.SendEmailFunc != nil {
+		return m.SendEmailFunc(ctx, id, emailAddress, options...)
+	}
+
+	var sent notify.SentEmail
+	sent.ID = newID()
+	sent.Template.ID = id
+	return sent, nil
+}
+
+func (m *Mock) SendSMS(id, phoneNumber string, options ...notify.SendSMSOption) (notify.SentSMS, error) {
+	return m.SendSMSContext(context.Background(), id, phoneNumber, options...)
+}
+
+func (m *Mock) SendSMSContext(ctx context.Context, id, phoneNumber string, options ...notify.SendSMSOption) (notify.SentSMS, error) {
+	m.record("SendSMS", id, phoneNumber, options)
+	if m.SendSMSFunc != nil {
+		return m.SendSMSFunc(ctx, id, phoneNumber, options...)
+	}
+
+	var sent notify.SentSMS
+	sent.ID = newID()
+	sent.Template.ID = id
+	return sent, nil
+}
+
+func (m *Mock) GetTemplateByID(id string) (notify.Template, error) {
+	return m.GetTemplateByIDContext(context.Background(), id)
+}
+
+func (m *Mock) GetTemplateByIDContext(ctx context.Context, id string) (notify.Template, error) {
+	m.record("GetTemplateByID", id)
+	if m.GetTemplateByIDFunc != nil {
+		return m.GetTemplateByIDFunc(ctx, id)
+	}
+	return notify.Template{ID: id}, nil
+}
+
+func (m *Mock) GetTemplateByIDAndVersion(id string, version int) (notify.Template, error) {
+	return m.GetTemplateByIDAndVersionContext(context.Background(), id, version)
+}
+
+func (m *Mock) GetTemplateByIDAndVersionContext(ctx context.Context, id string, version int) (notify.Template, error) {
+	m.record("GetTemplateByIDAndVersion", id, version)
+	if m.GetTemplateByIDAndVersionFunc != nil {
+		return m.GetTemplateByIDAndVersionFunc(ctx, id, version)
+	}
+	return notify.Template{ID: id, Version: version}, nil
+}
+
+func (m *Mock) GetAllTemplates(typ notify.TemplateType) (notify.Templates, error) {
+	return m.GetAllTemplatesContext(context.Background(), typ)
+}
+
+func (m *Mock) GetAllTemplatesContext(ctx context.Context, typ notify.TemplateType) (notify.Templates, error) {
+	m.record("GetAllTemplates", typ)
+	if m.GetAllTemplatesFunc != nil {
+		return m.GetAllTemplatesFunc(ctx, typ)
+	}
+	return notify.Templates{}, nil
+}
+
+func (m *Mock) GenerateTemplatePreview(id string, personalisation ...notify.PersonalisationOption) (notify.TemplatePreview, error) {
+	return m.GenerateTemplatePreviewContext(context.Background(), id, personalisation...)
+}
+
+func (m *Mock) GenerateTemplatePreviewContext(ctx context.Context, id string, personalisation ...notify.PersonalisationOption) (notify.TemplatePreview, error) {
+	m.record("GenerateTemplatePreview", id, personalisation)
+	if m.GenerateTemplatePreviewFunc != nil {
+		return m.GenerateTemplatePreviewFunc(ctx, id, personalisation...)
+	}
+	return notify.TemplatePreview{ID: id}, nil
+}
+
+func (m *Mock) GetNotificationById(id string) (notify.Notification, error) {
+	return m.GetNotificationByIdContext(context.Background(), id)
+}
+
+func (m *Mock) GetNotificationByIdContext(ctx context.Context, id string) (notify.Notification, error) {
+	m.record("GetNotificationById", id)
+	if m.GetNotificationByIdFunc != nil {
+		return m.GetNotificationByIdFunc(ctx, id)
+	}
+	return notify.Notification{ID: id}, nil
+}
+
+func (m *Mock) GetNotifications(filters ...notify.NotificationsFilter) (notify.NotificationsPage, error) {
+	return m.GetNotificationsContext(context.Background(), filters...)
+}
+
+func (m *Mock) GetNotificationsContext(ctx context.Context, filters ...notify.NotificationsFilter) (notify.NotificationsPage, error) {
+	m.record("GetNotifications", filters)
+	if m.GetNotificationsFunc != nil {
+		return m.GetNotificationsFunc(ctx, filters...)
+	}
+	return notify.NotificationsPage{}, nil
+}
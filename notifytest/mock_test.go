@@ -0,0 +1,50 @@
+package notifytest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/notifytest"
+)
+
+// remind is an example of code under test which depends on notify.SMSSender.
+func remind(sender notify.SMSSender, phoneNumber, name string) error {
+	_, err := sender.SendSMS("reminder-template", phoneNumber, notify.Personalisation{
+		{"name", name},
+	})
+	return err
+}
+
+func TestMock(t *testing.T) {
+	mock := &notifytest.Mock{}
+
+	if err := remind(mock, "0400000000", "Kim"); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := mock.CallsTo("SendSMS")
+	if len(calls) != 1 {
+		t.Fatalf("got %d calls, want 1", len(calls))
+	}
+	if calls[0].Args[0] != "reminder-template" || calls[0].Args[1] != "0400000000" {
+		t.Errorf("unexpected arguments %v", calls[0].Args)
+	}
+
+	options := calls[0].Args[2].([]notify.SendSMSOption)
+	personalisation, ok := options[0].(notify.Personalisation)
+	if !ok || personalisation[0].Value != "Kim" {
+		t.Errorf("unexpected personalisation %v", options)
+	}
+
+	mock.SendSMSFunc = func(ctx context.Context, id, phoneNumber string, options ...notify.SendSMSOption) (notify.SentSMS, error) {
+		return notify.SentSMS{}, errors.New("rate limited")
+	}
+	if err := remind(mock, "0400000000", "Sam"); err == nil {
+		t.Error("expected the error from SendSMSFunc")
+	}
+	if got := len(mock.Calls()); got != 2 {
+		t.Errorf("got %d calls, want 2", got)
+	}
+}
@@ -0,0 +1,190 @@
+// Package notifytest provides an in-process fake of the Notify API for tests.
+//
+//	server := notifytest.NewServer()
+//	defer server.Close()
+//
+//	template := server.AddTemplate(notify.Template{
+//		Type: "sms",
+//		Body: "Hello ((name))",
+//	})
+//
+//	client, err := server.Client()
+//	...
+//	sent := server.SentSMS()
+package notifytest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	notify "github.com/govau/notify-client-go"
+)
+
+// Message is a notification sent through the fake server.
+type Message struct {
+	notify.Notification
+
+	// Personalisation is the personalisation sent with the notification.
+	Personalisation map[string]interface{}
+}
+
+// Server is a fake Notify API. It accepts requests signed with its APIKey,
+// renders templates added with AddTemplate and records every notification sent
+// to it.
+type Server struct {
+	// URL is the base URL of the server, for use with notify.WithBaseURL.
+	URL string
+	// APIKey is an API key which the server accepts.
+	APIKey string
+
+	server    *httptest.Server
+	closed    chan struct{}
+	serviceID string
+	secret    string
+
+	mu        sync.Mutex
+	templates map[string][]notify.Template
+	order     []string
+	messages  []Message
+	calls     map[Endpoint]int
+	scenarios []Scenario
+}
+
+// NewServer starts a fake Notify server. The caller should call Close when
+// finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		serviceID: newID(),
+		secret:    newID(),
+		closed:    make(chan struct{}),
+		templates: map[string][]notify.Template{},
+		calls:     map[Endpoint]int{},
+	}
+	s.APIKey = "notifytest-" + s.serviceID + "-" + s.secret
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	close(s.closed)
+	s.server.Close()
+}
+
+// Client returns a client which sends requests to the server.
+func (s *Server) Client(options ...notify.ClientOption) (*notify.Client, error) {
+	return notify.NewClient(s.APIKey, append([]notify.ClientOption{notify.WithBaseURL(s.URL)}, options...)...)
+}
+
+// AddTemplate adds a template to the server and returns it as the server
+// stores it. If t has no ID, a new one is generated. Adding a template with an
+// existing ID creates a new version of that template.
+func (s *Server) AddTemplate(t notify.Template) notify.Template {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.ID == "" {
+		t.ID = newID()
+	}
+	if len(s.templates[t.ID]) == 0 {
+		s.order = append(s.order, t.ID)
+	}
+	t.Version = len(s.templates[t.ID]) + 1
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now()
+	}
+
+	s.templates[t.ID] = append(s.templates[t.ID], t)
+	return t
+}
+
+// Messages returns every notification sent to the server, oldest first.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.messages...)
+}
+
+// SentEmails returns the emails sent to the server, oldest first.
+func (s *Server) SentEmails() []Message {
+	return s.messagesOfType(notify.TemplateTypeEmail)
+}
+
+// SentSMS returns the text messages sent to the server, oldest first.
+func (s *Server) SentSMS() []Message {
+	return s.messagesOfType(notify.TemplateTypeSMS)
+}
+
+func (s *Server) messagesOfType(typ notify.TemplateType) []Message {
+	var messages []Message
+	for _, m := range s.Messages() {
+		if m.Type == typ {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// SetStatus changes the status of a notification sent to the server, such as
+// to simulate it being delivered. The notification's SentAt time is set when
+// it first leaves the created status, and its CompletedAt time when it reaches
+// a final status.
+func (s *Server) SetStatus(id string, status notify.NotificationStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.messages {
+		m := &s.messages[i]
+		if m.ID == id {
+			m.Status = status
+			if m.SentAt == nil && status != notify.StatusCreated {
+				sentAt := now()
+				m.SentAt = &sentAt
+			}
+			if status.IsFinal() {
+				completedAt := now()
+				m.CompletedAt = &completedAt
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("notifytest: no notification with id %s", id)
+}
+
+func (s *Server) template(id string, version int) (notify.Template, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.templates[id]
+	if len(versions) == 0 {
+		return notify.Template{}, false
+	}
+	if version == 0 {
+		return versions[len(versions)-1], true
+	}
+	if version < 1 || version > len(versions) {
+		return notify.Template{}, false
+	}
+	return versions[version-1], true
+}
+
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// now returns the current time with the precision Notify uses.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,266 @@
+package notifytest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/notifyapi"
+	"github.com/govau/notify-client-go/notifytest"
+)
+
+func TestServer(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	emailTemplate := server.AddTemplate(notify.Template{
+		Name:    "go-sdk-test-email",
+		Type:    "email",
+		Subject: "Colours for ((name))",
+		Body:    "Hi ((name)),\r\n\r\nMy favourite colours are:\r\n\r\n((colours))",
+	})
+	smsTemplate := server.AddTemplate(notify.Template{
+		Name: "go-sdk-test-sms",
+		Type: "sms",
+		Body: "Hello ((name)),\r\n\r\nToday is ((day)).",
+	})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	email, err := client.SendEmail(
+		emailTemplate.ID,
+		"someone@example.com",
+		notify.Personalisation{
+			{"name", "Kim"},
+			{"colours", []string{"pink", "blue"}},
+		},
+		notify.Reference("TestServer"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Hi Kim,\n\nMy favourite colours are:\n\n• pink\n• blue\n"; email.Content.Body != want {
+		t.Errorf("got body %q, want %q", email.Content.Body, want)
+	}
+	if want := "Colours for Kim"; email.Content.Subject != want {
+		t.Errorf("got subject %q, want %q", email.Content.Subject, want)
+	}
+
+	when := time.Now().Add(time.Hour)
+	sms, err := client.SendSMS(
+		smsTemplate.ID,
+		"0400000000",
+		notify.Personalisation{
+			{"name", "Kim"},
+			{"day", "Friday"},
+		},
+		notify.ScheduledFor(when),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Hello Kim,\n\nToday is Friday."; sms.Content.Body != want {
+		t.Errorf("got body %q, want %q", sms.Content.Body, want)
+	}
+	if sent := server.SentSMS(); len(sent) != 1 || sent[0].ScheduledFor == nil || !sent[0].ScheduledFor.Equal(when.Truncate(time.Minute)) {
+		t.Errorf("unexpected sent text messages %+v, want one scheduled for %v", sent, when.Truncate(time.Minute))
+	}
+
+	sent := server.SentEmails()
+	if len(sent) != 1 || sent[0].EmailAddress != "someone@example.com" || sent[0].Reference != "TestServer" {
+		t.Errorf("unexpected sent emails %+v", sent)
+	}
+	if got := sent[0].Personalisation["name"]; got != "Kim" {
+		t.Errorf("got personalisation name %v, want Kim", got)
+	}
+
+	if err := server.SetStatus(sms.ID, "delivered"); err != nil {
+		t.Fatal(err)
+	}
+	notification, err := client.GetNotificationById(sms.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notification.Status != "delivered" {
+		t.Errorf("got status %v, want delivered", notification.Status)
+	}
+
+	page, err := client.GetNotifications(notify.FilterByTemplateType("email"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Notifications) != 1 || page.Notifications[0].ID != email.ID {
+		t.Errorf("unexpected notifications %+v", page.Notifications)
+	}
+
+	templates, err := client.GetAllTemplates("sms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].ID != smsTemplate.ID {
+		t.Errorf("unexpected templates %+v", templates)
+	}
+
+	preview, err := client.GenerateTemplatePreview(smsTemplate.ID, notify.Personalisation{
+		{"name", "KD"},
+		{"day", "Monday"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Hello KD,\n\nToday is Monday."; preview.Body != want {
+		t.Errorf("got preview %q, want %q", preview.Body, want)
+	}
+}
+
+func TestServerTemplateVersions(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	v1 := server.AddTemplate(notify.Template{Type: "sms", Body: "Version one"})
+	server.AddTemplate(notify.Template{ID: v1.ID, Type: "sms", Body: "Version two"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := client.GetTemplateByID(v1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Version != 2 || latest.Body != "Version two" {
+		t.Errorf("unexpected latest template %+v", latest)
+	}
+
+	first, err := client.GetTemplateByIDAndVersion(v1.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Body != "Version one" {
+		t.Errorf("unexpected first version %+v", first)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Hello ((name))"})
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.SendSMS(template.ID, "0400000000")
+	apiErr, ok := err.(*notifyapi.Error)
+	if !ok || apiErr.Code != 400 {
+		t.Errorf("got error %v, want a 400 for missing personalisation", err)
+	}
+
+	other := notifytest.NewServer()
+	defer other.Close()
+
+	wrongKey, err := notify.NewClient(other.APIKey, notify.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wrongKey.GetTemplateByID(template.ID)
+	apiErr, ok = err.(*notifyapi.Error)
+	if !ok || apiErr.Code != 403 {
+		t.Errorf("got error %v, want a 403 for the wrong API key", err)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	server := notifytest.NewServer()
+	defer server.Close()
+
+	template := server.AddTemplate(notify.Template{Type: "sms", Body: "Hello"})
+
+	client, err := server.Client(notify.WithRetryPolicy(notify.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Inject(notifytest.Scenario{
+		Endpoint: notifytest.SendSMS,
+		Fault:    notifytest.RateLimited(0),
+		Times:    2,
+	})
+	if _, err := client.SendSMS(template.ID, "0400000000"); err != nil {
+		t.Fatalf("expected the send to succeed after retrying, got %v", err)
+	}
+	if got := server.Calls(notifytest.SendSMS); got != 3 {
+		t.Errorf("got %d calls, want 3", got)
+	}
+	if got := len(server.SentSMS()); got != 1 {
+		t.Errorf("got %d sent messages, want 1", got)
+	}
+
+	server.Inject(notifytest.Scenario{
+		Endpoint: notifytest.GetTemplate,
+		Fault:    notifytest.RateLimited(100 * time.Millisecond),
+		Times:    1,
+	})
+	_, err = client.GetTemplateByIDContext(notify.WithoutRetry(context.Background()), template.ID)
+	if apiErr, ok := err.(*notifyapi.Error); !ok || apiErr.Header.Get("Retry-After") != "1" {
+		t.Errorf("got error %v, want a Retry-After of 1 second", err)
+	}
+
+	server.Inject(notifytest.Scenario{
+		Endpoint: notifytest.SendSMS,
+		Fault:    notifytest.ValidationError("phone_number Not a valid number"),
+		From:     5,
+		Times:    1,
+	})
+	if _, err := client.SendSMS(template.ID, "0400000000"); err != nil {
+		t.Errorf("call 4: unexpected error %v", err)
+	}
+	_, err = client.SendSMS(template.ID, "0400000000")
+	if apiErr, ok := err.(*notifyapi.Error); !ok || apiErr.Code != 400 || apiErr.Errors[0].Error != "ValidationError" {
+		t.Errorf("call 5: got error %v, want a validation error", err)
+	}
+
+	server.Inject(notifytest.Scenario{
+		Endpoint: notifytest.GetTemplate,
+		Fault:    notifytest.ServerError(502, "<html>Bad Gateway</html>"),
+		Times:    1,
+	})
+	if _, err := client.GetTemplateByIDContext(notify.WithoutRetry(context.Background()), template.ID); err == nil {
+		t.Error("expected an error for a 502")
+	}
+
+	server.Inject(notifytest.Scenario{
+		Endpoint: notifytest.AnyEndpoint,
+		Fault:    notifytest.Timeout(),
+		Times:    1,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetTemplateByIDContext(notify.WithoutRetry(ctx), template.ID); err == nil {
+		t.Error("expected an error for a timeout")
+	}
+
+	server.ClearFaults()
+	server.Inject(notifytest.Scenario{
+		Endpoint: notifytest.GetTemplate,
+		Fault:    notifytest.Slow(20 * time.Millisecond),
+	})
+	start := time.Now()
+	if _, err := client.GetTemplateByID(template.ID); err != nil {
+		t.Errorf("unexpected error from a slow response %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("slow response took %v, want at least 20ms", elapsed)
+	}
+}
@@ -19,6 +19,29 @@ func (p payload) MarshalJSON() ([]byte, error) {
 	return json.Marshal(dict)
 }
 
+// validate checks the values in the payload which can be checked before the
+// payload is sent.
+func (p payload) validate() error {
+	for _, item := range p {
+		if v, ok := item.message.(interface{ validate() error }); ok {
+			if err := v.validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// personalisation returns the personalisation in the payload, if any.
+func (p payload) personalisation() map[string]interface{} {
+	for i := len(p) - 1; i >= 0; i-- {
+		if dict, ok := p[i].message.(map[string]interface{}); ok && p[i].field == "personalisation" {
+			return dict
+		}
+	}
+	return nil
+}
+
 // Personalisation is a slice of structs used to define placeholder values in a
 // template, such as name or reference number.
 // The struct should be structured such that the key is the name of the value
@@ -47,6 +70,10 @@ func (personalisation Personalisation) updateEmailPayload(p payload) payload {
 	return personalisation.updatePayload(p)
 }
 
+func (personalisation Personalisation) updateLetterPayload(p payload) payload {
+	return personalisation.updatePayload(p)
+}
+
 func (personalisation Personalisation) updatePersonalisationPayload(p payload) payload {
 	return personalisation.updatePayload(p)
 }
@@ -75,6 +102,13 @@ func EmailReplyToID(address string) SendEmailOption {
 	})
 }
 
+// Postage sets the class of postage used to send a letter.
+func Postage(class PostageClass) LetterOption {
+	return updatePayloadFunc(func(p payload) payload {
+		return append(p, payloadItem{"postage", class})
+	})
+}
+
 // SMSSenderID is a unique identifier for the sender of a text message.
 func SMSSenderID(senderID string) SendSMSOption {
 	return updatePayloadFunc(func(p payload) payload {
@@ -85,6 +119,12 @@ func SMSSenderID(senderID string) SendSMSOption {
 type CommonOption interface {
 	SendSMSOption
 	SendEmailOption
+	SendLetterOption
+}
+
+type LetterOption interface {
+	SendLetterOption
+	SendPrecompiledLetterOption
 }
 
 type SendSMSOption interface {
@@ -95,6 +135,14 @@ type SendEmailOption interface {
 	updateEmailPayload(payload) payload
 }
 
+type SendLetterOption interface {
+	updateLetterPayload(payload) payload
+}
+
+type SendPrecompiledLetterOption interface {
+	updatePrecompiledLetterPayload(payload) payload
+}
+
 type PersonalisationOption interface {
 	updatePersonalisationPayload(payload) payload
 }
@@ -108,3 +156,11 @@ func (fn updatePayloadFunc) updateSMSPayload(p payload) payload {
 func (fn updatePayloadFunc) updateEmailPayload(p payload) payload {
 	return fn(p)
 }
+
+func (fn updatePayloadFunc) updateLetterPayload(p payload) payload {
+	return fn(p)
+}
+
+func (fn updatePayloadFunc) updatePrecompiledLetterPayload(p payload) payload {
+	return fn(p)
+}
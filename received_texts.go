@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/govau/notify-client-go/internal/base"
+)
+
+// ReceivedText is a text message sent by a user to your service's inbound
+// number.
+type ReceivedText struct {
+	ID           string    `json:"id"`
+	UserNumber   string    `json:"user_number"`
+	NotifyNumber string    `json:"notify_number"`
+	Content      string    `json:"content"`
+	CreatedAt    time.Time `json:"created_at"`
+	ServiceID    string    `json:"service_id"`
+}
+
+// ReceivedTextsPage is a single page of received text messages, newest first.
+type ReceivedTextsPage struct {
+	ReceivedTexts []ReceivedText `json:"received_text_messages"`
+	Links         Links          `json:"links"`
+}
+
+// GetReceivedTexts returns a page of received text messages. If olderThan is
+// not empty, only messages received before the message with that ID are
+// returned.
+func (c Client) GetReceivedTexts(olderThan string) (ReceivedTextsPage, error) {
+	return c.GetReceivedTextsContext(context.Background(), olderThan)
+}
+
+func (c Client) GetReceivedTextsContext(ctx context.Context, olderThan string) (ReceivedTextsPage, error) {
+	var query base.QueryValues
+	if olderThan != "" {
+		query = append(query, queryValue("older_than", olderThan))
+	}
+
+	var page ReceivedTextsPage
+	err := c.c.GetContext(ctx, "./v2/received-text-messages", query).JSON(&page).Error
+	return page, err
+}
+
+// ReceivedTextIterator walks through every page of received text messages.
+type ReceivedTextIterator struct {
+	client Client
+	ctx    context.Context
+
+	page      []ReceivedText
+	current   ReceivedText
+	olderThan string
+	last      bool
+	err       error
+}
+
+// ReceivedTexts returns an iterator over all received text messages, fetching
+// further pages as they are needed.
+func (c Client) ReceivedTexts(ctx context.Context) *ReceivedTextIterator {
+	return &ReceivedTextIterator{client: c, ctx: ctx}
+}
+
+// Next advances the iterator to the next received text message. It returns
+// false when there are no more messages or an error occurred.
+func (it *ReceivedTextIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.last || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *ReceivedTextIterator) fetch() {
+	page, err := it.client.GetReceivedTextsContext(it.ctx, it.olderThan)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page = page.ReceivedTexts
+	it.olderThan = page.Links.olderThan()
+	if it.olderThan == "" && page.Links.Next != "" && len(page.ReceivedTexts) > 0 {
+		it.olderThan = page.ReceivedTexts[len(page.ReceivedTexts)-1].ID
+	}
+	it.last = it.olderThan == "" || len(page.ReceivedTexts) == 0
+}
+
+// ReceivedText returns the message the iterator is currently at.
+func (it *ReceivedTextIterator) ReceivedText() ReceivedText {
+	return it.current
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *ReceivedTextIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MaxSchedule is how far in the future a notification can be scheduled.
+const MaxSchedule = 24 * time.Hour
+
+// ScheduledForLayout is the format of scheduled times sent to and returned by
+// Notify, to the minute.
+const ScheduledForLayout = "2006-01-02 15:04"
+
+// ScheduleTimeZone is the time zone Notify reads scheduled times in.
+const ScheduleTimeZone = "Australia/Sydney"
+
+var (
+	scheduleLocationOnce sync.Once
+	scheduleLocation     *time.Location
+	scheduleLocationErr  error
+)
+
+// loadScheduleLocation returns the location of ScheduleTimeZone, which
+// requires the system's time zone database.
+func loadScheduleLocation() (*time.Location, error) {
+	scheduleLocationOnce.Do(func() {
+		scheduleLocation, scheduleLocationErr = time.LoadLocation(ScheduleTimeZone)
+		if scheduleLocationErr != nil {
+			scheduleLocationErr = fmt.Errorf("notify: loading scheduled time zone: %v", scheduleLocationErr)
+		}
+	})
+	return scheduleLocation, scheduleLocationErr
+}
+
+// ScheduledFor schedules a notification to be sent at the given time, which
+// is truncated to the minute. It must be in the future and no more than
+// MaxSchedule from now when the notification is sent.
+func ScheduledFor(t time.Time) CommonOption {
+	t = t.Truncate(time.Minute)
+	return updatePayloadFunc(func(p payload) payload {
+		return append(p, payloadItem{"scheduled_for", scheduledTime(t)})
+	})
+}
+
+// scheduledTime is the time a notification is scheduled to be sent.
+type scheduledTime time.Time
+
+func (t scheduledTime) validate() error {
+	if _, err := loadScheduleLocation(); err != nil {
+		return err
+	}
+
+	until := time.Until(time.Time(t))
+	if until <= 0 {
+		return fmt.Errorf("notify: scheduled time %s is not in the future", time.Time(t).Format(time.RFC3339))
+	}
+	if until > MaxSchedule {
+		return fmt.Errorf("notify: scheduled time %s is more than %s in the future", time.Time(t).Format(time.RFC3339), MaxSchedule)
+	}
+	return nil
+}
+
+func (t scheduledTime) MarshalJSON() ([]byte, error) {
+	location, err := loadScheduleLocation()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(time.Time(t).In(location).Format(ScheduledForLayout))
+}
+
+// scheduledTimeField decodes the scheduled_for field of a send response into
+// a *time.Time.
+type scheduledTimeField struct {
+	t **time.Time
+}
+
+func (f scheduledTimeField) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == nil {
+		*f.t = nil
+		return nil
+	}
+
+	location, err := loadScheduleLocation()
+	if err != nil {
+		return err
+	}
+
+	t, err := time.ParseInLocation(ScheduledForLayout, *value, location)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, *value)
+	}
+	if err != nil {
+		return fmt.Errorf("notify: invalid scheduled_for time %q", *value)
+	}
+	*f.t = &t
+	return nil
+}
+
+func (s *SentSMS) UnmarshalJSON(data []byte) error {
+	type sentSMS SentSMS
+	return json.Unmarshal(data, &struct {
+		*sentSMS
+		ScheduledFor scheduledTimeField `json:"scheduled_for"`
+	}{(*sentSMS)(s), scheduledTimeField{&s.ScheduledFor}})
+}
+
+func (s *SentEmail) UnmarshalJSON(data []byte) error {
+	type sentEmail SentEmail
+	return json.Unmarshal(data, &struct {
+		*sentEmail
+		ScheduledFor scheduledTimeField `json:"scheduled_for"`
+	}{(*sentEmail)(s), scheduledTimeField{&s.ScheduledFor}})
+}
+
+func (s *SentLetter) UnmarshalJSON(data []byte) error {
+	type sentLetter SentLetter
+	return json.Unmarshal(data, &struct {
+		*sentLetter
+		ScheduledFor scheduledTimeField `json:"scheduled_for"`
+	}{(*sentLetter)(s), scheduledTimeField{&s.ScheduledFor}})
+}
@@ -0,0 +1,61 @@
+// Package template renders Notify templates locally, without a request to the
+// preview endpoint.
+//
+// Templates use Notify's placeholder syntax:
+//
+//	Hello ((name))                  replaced with the value of name
+//	((has_card??Bring your card.))  shown when has_card is yes or true
+//	((colours))                     a list, rendered as bullet points in an
+//	                                email and as "a, b and c" in a text
+//	                                message
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/internal/placeholder"
+)
+
+// MissingPersonalisationError is returned when a template has placeholders
+// which are not in the personalisation.
+type MissingPersonalisationError struct {
+	Missing []string
+}
+
+func (e MissingPersonalisationError) Error() string {
+	return fmt.Sprintf("template: missing personalisation: %s", strings.Join(e.Missing, ", "))
+}
+
+// Placeholders returns the names of the placeholders in t's subject and body,
+// in the order they first appear.
+func Placeholders(t notify.Template) []string {
+	return placeholder.Names(t.Subject, t.Body)
+}
+
+// Render renders t with personalisation, producing the same output as
+// GenerateTemplatePreview. If any placeholders are missing from the
+// personalisation, the preview is still returned with those placeholders left
+// in place, along with a MissingPersonalisationError.
+func Render(t notify.Template, personalisation notify.Personalisation) (notify.TemplatePreview, error) {
+	values := map[string]interface{}{}
+	for _, item := range personalisation {
+		values[item.Key] = item.Value
+	}
+
+	subject, body, missing := placeholder.RenderTemplate(string(t.Type), t.Subject, t.Body, values)
+
+	preview := notify.TemplatePreview{
+		ID:      t.ID,
+		Type:    t.Type,
+		Version: t.Version,
+		Subject: subject,
+		Body:    body,
+	}
+
+	if len(missing) > 0 {
+		return preview, MissingPersonalisationError{Missing: missing}
+	}
+	return preview, nil
+}
@@ -0,0 +1,167 @@
+package template_test
+
+import (
+	"reflect"
+	"testing"
+
+	notify "github.com/govau/notify-client-go"
+	"github.com/govau/notify-client-go/template"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name            string
+		template        notify.Template
+		personalisation notify.Personalisation
+		wantSubject     string
+		wantBody        string
+	}{
+		{
+			name: "sms",
+			template: notify.Template{
+				Type: "sms",
+				Body: "Hello ((name)),\r\n\r\nToday is ((day)).",
+			},
+			personalisation: notify.Personalisation{
+				{"name", "Kim"},
+				{"day", "Friday"},
+			},
+			wantBody: "Hello Kim,\n\nToday is Friday.",
+		},
+		{
+			name: "email with a list",
+			template: notify.Template{
+				Type:    "email",
+				Subject: "Colours for ((name))",
+				Body:    "Hi ((name)),\r\n\r\nMy favourite colours are:\r\n\r\n((colours))",
+			},
+			personalisation: notify.Personalisation{
+				{"name", "Kim"},
+				{"colours", []string{"pink", "blue"}},
+			},
+			wantSubject: "Colours for Kim",
+			wantBody:    "Hi Kim,\n\nMy favourite colours are:\n\n• pink\n• blue\n",
+		},
+		{
+			name: "sms with a list",
+			template: notify.Template{
+				Type: "sms",
+				Body: "Your colours: ((colours))",
+			},
+			personalisation: notify.Personalisation{
+				{"colours", []string{"pink", "blue"}},
+			},
+			wantBody: "Your colours: pink and blue",
+		},
+		{
+			name: "sms with a longer list",
+			template: notify.Template{
+				Type: "sms",
+				Body: "Your colours: ((colours))",
+			},
+			personalisation: notify.Personalisation{
+				{"colours", []string{"pink", "blue", "green"}},
+			},
+			wantBody: "Your colours: pink, blue and green",
+		},
+		{
+			name: "conditional shown",
+			template: notify.Template{
+				Type: "sms",
+				Body: "See you soon.((has_card?? Bring your card.))",
+			},
+			personalisation: notify.Personalisation{
+				{"has_card", "yes"},
+			},
+			wantBody: "See you soon. Bring your card.",
+		},
+		{
+			name: "conditional hidden",
+			template: notify.Template{
+				Type: "sms",
+				Body: "See you soon.((has_card?? Bring your card.))",
+			},
+			personalisation: notify.Personalisation{
+				{"has_card", false},
+			},
+			wantBody: "See you soon.",
+		},
+		{
+			name: "placeholder names are case insensitive",
+			template: notify.Template{
+				Type: "sms",
+				Body: "Hello ((Name))",
+			},
+			personalisation: notify.Personalisation{
+				{"name", "Kim"},
+			},
+			wantBody: "Hello Kim",
+		},
+		{
+			name: "placeholder names ignore spaces and underscores",
+			template: notify.Template{
+				Type: "sms",
+				Body: "Hello ((first name))",
+			},
+			personalisation: notify.Personalisation{
+				{"first_name", "Kim"},
+			},
+			wantBody: "Hello Kim",
+		},
+		{
+			name: "placeholder names ignore hyphens",
+			template: notify.Template{
+				Type: "sms",
+				Body: "Hello ((first name))",
+			},
+			personalisation: notify.Personalisation{
+				{"first-name", "Kim"},
+			},
+			wantBody: "Hello Kim",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := template.Render(tt.template, tt.personalisation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if preview.Subject != tt.wantSubject {
+				t.Errorf("got subject %q, want %q", preview.Subject, tt.wantSubject)
+			}
+			if preview.Body != tt.wantBody {
+				t.Errorf("got body %q, want %q", preview.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRenderMissingPersonalisation(t *testing.T) {
+	preview, err := template.Render(notify.Template{
+		Type:    "email",
+		Subject: "Hello ((name))",
+		Body:    "((name)), your reference is ((reference)).",
+	}, notify.Personalisation{})
+
+	missing, ok := err.(template.MissingPersonalisationError)
+	if !ok {
+		t.Fatalf("got error %v, want a MissingPersonalisationError", err)
+	}
+	if want := []string{"name", "reference"}; !reflect.DeepEqual(missing.Missing, want) {
+		t.Errorf("got missing %v, want %v", missing.Missing, want)
+	}
+	if want := "((name)), your reference is ((reference))."; preview.Body != want {
+		t.Errorf("got body %q, want %q", preview.Body, want)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	got := template.Placeholders(notify.Template{
+		Subject: "Your ((service)) appointment",
+		Body:    "Hi ((name)), your ((service)) appointment is on ((day)).((has_card??Bring your card.))((Has Card??))",
+	})
+	want := []string{"service", "name", "day", "has_card"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
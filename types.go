@@ -0,0 +1,84 @@
+package notify
+
+// TemplateType is the type of a template, and of the notifications sent with
+// it.
+type TemplateType string
+
+const (
+	TemplateTypeEmail  TemplateType = "email"
+	TemplateTypeSMS    TemplateType = "sms"
+	TemplateTypeLetter TemplateType = "letter"
+)
+
+// NotificationStatus is the delivery status of a notification.
+type NotificationStatus string
+
+const (
+	// StatusCreated means Notify has placed the notification in a queue, ready
+	// to be sent to the provider.
+	StatusCreated NotificationStatus = "created"
+	// StatusSending means Notify has sent the notification to the provider.
+	StatusSending NotificationStatus = "sending"
+	// StatusPending means Notify is waiting for more delivery information
+	// from the provider.
+	StatusPending NotificationStatus = "pending"
+	// StatusSent means a text message was sent to an international number,
+	// which may not provide any more delivery information.
+	StatusSent NotificationStatus = "sent"
+	// StatusDelivered means the notification was delivered to the recipient.
+	StatusDelivered NotificationStatus = "delivered"
+	// StatusPermanentFailure means the provider could not deliver the
+	// notification, because the address or number does not exist.
+	StatusPermanentFailure NotificationStatus = "permanent-failure"
+	// StatusTemporaryFailure means the provider could not deliver the
+	// notification, for example because the inbox was full or the phone was
+	// turned off.
+	StatusTemporaryFailure NotificationStatus = "temporary-failure"
+	// StatusTechnicalFailure means the notification was not sent because of a
+	// problem between Notify and the provider.
+	StatusTechnicalFailure NotificationStatus = "technical-failure"
+
+	// StatusPendingVirusCheck means Notify is checking a precompiled letter
+	// for viruses.
+	StatusPendingVirusCheck NotificationStatus = "pending-virus-check"
+	// StatusVirusScanFailed means Notify found a virus in a precompiled
+	// letter.
+	StatusVirusScanFailed NotificationStatus = "virus-scan-failed"
+	// StatusValidationFailed means a precompiled letter was not in the
+	// required format.
+	StatusValidationFailed NotificationStatus = "validation-failed"
+	// StatusAccepted means Notify is printing and posting the letter.
+	StatusAccepted NotificationStatus = "accepted"
+	// StatusReceived means the provider has printed and dispatched the
+	// letter.
+	StatusReceived NotificationStatus = "received"
+	// StatusCancelled means the letter was cancelled before it was printed.
+	StatusCancelled NotificationStatus = "cancelled"
+
+	// StatusFailed is used with FilterByStatus to match every failure status.
+	StatusFailed NotificationStatus = "failed"
+)
+
+// IsFinal reports whether a notification with status s will not change status
+// again.
+func (s NotificationStatus) IsFinal() bool {
+	switch s {
+	case StatusSent, StatusDelivered, StatusReceived, StatusCancelled:
+		return true
+	}
+	return s.IsFailure()
+}
+
+// IsFailure reports whether s means the notification was not delivered.
+func (s NotificationStatus) IsFailure() bool {
+	switch s {
+	case StatusPermanentFailure,
+		StatusTemporaryFailure,
+		StatusTechnicalFailure,
+		StatusVirusScanFailed,
+		StatusValidationFailed,
+		StatusFailed:
+		return true
+	}
+	return false
+}
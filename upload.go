@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MaxUploadSize is the largest file, in bytes, that can be sent by email.
+const MaxUploadSize = 2 * 1024 * 1024
+
+// ErrFileTooLarge is returned by PrepareUpload when the file is larger than
+// MaxUploadSize.
+var ErrFileTooLarge = errors.New("notify: file must be smaller than 2MB")
+
+// Upload is a file to be sent by email. Use it as the value of a placeholder
+// in Personalisation, and the email will contain a link to download the file.
+type Upload struct {
+	File                       string `json:"file"`
+	IsCSV                      bool   `json:"is_csv"`
+	Filename                   string `json:"filename,omitempty"`
+	ConfirmEmailBeforeDownload *bool  `json:"confirm_email_before_download,omitempty"`
+	RetentionPeriod            string `json:"retention_period,omitempty"`
+}
+
+type UploadOption func(*Upload) error
+
+// Filename sets the name the file will be downloaded as. It must include the
+// file extension.
+func Filename(name string) UploadOption {
+	return func(u *Upload) error {
+		u.Filename = name
+		return nil
+	}
+}
+
+// IsCSV marks the file as a CSV so that it is downloaded as a .csv file.
+func IsCSV(isCSV bool) UploadOption {
+	return func(u *Upload) error {
+		u.IsCSV = isCSV
+		return nil
+	}
+}
+
+// ConfirmEmailBeforeDownload controls whether the recipient must enter their
+// email address before downloading the file.
+func ConfirmEmailBeforeDownload(confirm bool) UploadOption {
+	return func(u *Upload) error {
+		u.ConfirmEmailBeforeDownload = &confirm
+		return nil
+	}
+}
+
+// RetentionPeriod sets how many weeks the file is available to download for,
+// between 1 and 78 weeks.
+func RetentionPeriod(weeks int) UploadOption {
+	return func(u *Upload) error {
+		if weeks < 1 || weeks > 78 {
+			return fmt.Errorf("notify: retention period must be between 1 and 78 weeks, got %d", weeks)
+		}
+
+		u.RetentionPeriod = fmt.Sprintf("%d weeks", weeks)
+		if weeks == 1 {
+			u.RetentionPeriod = "1 week"
+		}
+		return nil
+	}
+}
+
+// PrepareUpload reads a file to be sent by email and encodes it as a
+// personalisation value.
+func PrepareUpload(r io.Reader, options ...UploadOption) (Upload, error) {
+	var upload Upload
+	var buf bytes.Buffer
+
+	n, err := io.Copy(&buf, io.LimitReader(r, MaxUploadSize+1))
+	if err != nil {
+		return upload, err
+	}
+	if n > MaxUploadSize {
+		return upload, ErrFileTooLarge
+	}
+
+	upload.File = base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	for _, option := range options {
+		if err := option(&upload); err != nil {
+			return upload, err
+		}
+	}
+
+	return upload, nil
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/govau/notify-client-go/internal/placeholder"
+)
+
+// PersonalisationError is returned before sending a notification when its
+// personalisation does not match the placeholders in the template.
+type PersonalisationError struct {
+	TemplateID string
+	// Missing are placeholders in the template without a value.
+	Missing []string
+	// Unexpected are values which do not match a placeholder in the template.
+	Unexpected []string
+}
+
+func (e PersonalisationError) Error() string {
+	var problems []string
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unexpected) > 0 {
+		problems = append(problems, "unexpected "+strings.Join(e.Unexpected, ", "))
+	}
+	return fmt.Sprintf("notify: personalisation for template %s: %s", e.TemplateID, strings.Join(problems, "; "))
+}
+
+// validationTTL is how long templates are cached for validation when the
+// client has no template cache.
+const validationTTL = 5 * time.Minute
+
+// WithPersonalisationValidation checks the personalisation of every email and
+// text message against the placeholders in its template before sending it.
+// Templates are looked up in the client's template cache if it has one, and
+// are otherwise cached for five minutes, so that changes to a template are
+// picked up. InvalidateTemplate removes a template from either cache.
+func WithPersonalisationValidation() ClientOption {
+	return func(c Client) (Client, error) {
+		c.validator = &validator{templates: newTemplateCache(validationTTL)}
+		return c, nil
+	}
+}
+
+type validator struct {
+	templates *templateCache
+}
+
+// validatePersonalisation returns a PersonalisationError if the
+// personalisation in p does not match the placeholders in the template.
+func (c Client) validatePersonalisation(ctx context.Context, id string, p payload) error {
+	if c.validator == nil {
+		return nil
+	}
+
+	templates := c.templates
+	if templates == nil {
+		templates = c.validator.templates
+	}
+
+	template, err := templates.getTemplate(ctx, c, id)
+	if err != nil {
+		return err
+	}
+	names := placeholder.Names(template.Subject, template.Body)
+
+	// values maps the normalised keys of the personalisation to the keys as
+	// given, in lower case.
+	values := map[string]string{}
+	for key := range p.personalisation() {
+		values[placeholder.Key(key)] = strings.ToLower(key)
+	}
+
+	var e = PersonalisationError{TemplateID: id}
+
+	expected := map[string]bool{}
+	for _, name := range names {
+		expected[placeholder.Key(name)] = true
+		if _, ok := values[placeholder.Key(name)]; !ok {
+			e.Missing = append(e.Missing, name)
+		}
+	}
+	for key, given := range values {
+		if !expected[key] {
+			e.Unexpected = append(e.Unexpected, given)
+		}
+	}
+	sort.Strings(e.Unexpected)
+
+	if len(e.Missing) > 0 || len(e.Unexpected) > 0 {
+		return e
+	}
+	return nil
+}
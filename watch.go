@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+// minPollInterval is the shortest time WatchNotification waits between checks.
+const minPollInterval = 10 * time.Millisecond
+
+type watchConfig struct {
+	minInterval time.Duration
+	maxInterval time.Duration
+}
+
+type WatchOption func(*watchConfig)
+
+// PollInterval sets how often WatchNotification checks the notification. The
+// interval starts at min and doubles after each check until it reaches max.
+// min is at least 10ms, and max is at least min.
+func PollInterval(min, max time.Duration) WatchOption {
+	return func(c *watchConfig) {
+		if min < minPollInterval {
+			min = minPollInterval
+		}
+		if max < min {
+			max = min
+		}
+		c.minInterval = min
+		c.maxInterval = max
+	}
+}
+
+// WatchNotification polls a notification until its status is final, calling
+// onChange with the notification when it is first fetched and whenever its
+// status changes. It returns the notification in its final state, or the last
+// state seen if ctx is done first.
+func (c Client) WatchNotification(
+	ctx context.Context,
+	id string,
+	onChange func(Notification),
+	options ...WatchOption,
+) (Notification, error) {
+	config := watchConfig{
+		minInterval: time.Second,
+		maxInterval: 30 * time.Second,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+
+	var last Notification
+	interval := config.minInterval
+
+	for first := true; ; first = false {
+		n, err := c.GetNotificationByIdContext(ctx, id)
+		if err != nil {
+			return last, err
+		}
+
+		if first || n.Status != last.Status {
+			if onChange != nil {
+				onChange(n)
+			}
+		}
+		last = n
+
+		if n.Status.IsFinal() {
+			return n, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > config.maxInterval {
+			interval = config.maxInterval
+		}
+	}
+}